@@ -0,0 +1,142 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var askCmd = &cobra.Command{
+	Use:   "ask [message]",
+	Short: "Sends a single message to the cat and prints the reply",
+	Long: `Sends a single message to the cat and prints the reply.
+
+The message is read from the argument, from the file passed with --file,
+or from stdin when the argument is "-" or omitted with a piped input.
+The command exits with a non zero code on API errors, so it can be used in scripts.
+Use the global --json flag to print the full response, including the why details.`,
+	Example: `meow ask "What time is it?"
+echo "Summarize the docs" | meow ask --user-id ci --timeout 2m`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  executeAsk,
+}
+
+var askCmdFlags struct {
+	file    string
+	timeout time.Duration
+}
+
+func init() {
+	rootCmd.AddCommand(askCmd)
+
+	addCatAPIFlags(askCmd.Flags())
+	askCmd.Flags().StringVarP(&askCmdFlags.file, "file", "f", "", "Read the message from the file")
+	askCmd.Flags().DurationVar(&askCmdFlags.timeout, "timeout", time.Minute, "Maximum time to wait for the reply")
+}
+
+// executeAsk performs the "ask" logic.
+func executeAsk(cmd *cobra.Command, args []string) {
+	instance, err := resolveCatInstance(cmd)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	message, err := readAskMessage(args)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	err = runAsk(cmd.Context(), instance, message)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+// readAskMessage returns the message from the argument, the file or stdin.
+func readAskMessage(args []string) (string, error) {
+	hasArgument := len(args) > 0 && args[0] != "-"
+	if hasArgument && askCmdFlags.file != "" {
+		return "", errors.New("the message argument and --file are incompatible")
+	}
+
+	var message []byte
+	var err error
+	switch {
+	case hasArgument:
+		message = []byte(args[0])
+	case askCmdFlags.file != "":
+		message, err = os.ReadFile(askCmdFlags.file)
+	case len(args) > 0 || isPipedStdin():
+		message, err = io.ReadAll(os.Stdin)
+	default:
+		return "", errors.New("no message provided, pass it as argument, with --file or through stdin")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	text := strings.TrimSpace(string(message))
+	if text == "" {
+		return "", errors.New("the message is empty")
+	}
+
+	return text, nil
+}
+
+// isPipedStdin checks whether stdin is not an interactive terminal.
+func isPipedStdin() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice == 0
+}
+
+func runAsk(ctx context.Context, instance catInstance, message string) error {
+	ctx, cancel := context.WithTimeout(ctx, askCmdFlags.timeout)
+	defer cancel()
+
+	catClient, err := newCatClient(instance)
+	if err != nil {
+		return err
+	}
+
+	reply, err := catClient.SendMessage(ctx, message)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("no reply from the cat within %s", askCmdFlags.timeout)
+	}
+	if err != nil {
+		return err
+	}
+
+	if globalFlags.json {
+		return printJSON(reply)
+	}
+
+	fmt.Println(reply.Message())
+	return nil
+}
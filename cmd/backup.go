@@ -0,0 +1,344 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/cobra"
+
+	"github.com/saniales/meow-cli/pkg/backup"
+	"github.com/saniales/meow-cli/pkg/providers/docker"
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Backs up the plugins, data and static folders of the cat",
+	Long: `Backs up the plugins, data and static folders of the cat in a single tar.gz archive,
+along with a manifest holding the effective instance settings (secrets excluded),
+the image digest and the checksums of all the files.
+
+The cat container is stopped during the backup, so that the files are consistent,
+and started again at the end. With --pause the container is paused instead,
+which is faster but leaves the open connections hanging.`,
+	Example: `meow backup
+meow backup --instance staging --output staging.tar.gz --pause`,
+	Args: cobra.NoArgs,
+	Run:  executeBackup,
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <archive>",
+	Short: "Restores a backup of the cat",
+	Long: `Restores a backup made with "meow backup" into the folders of the instance,
+then starts the cat with the image of the backup.
+
+The instance is recreated from the settings saved in the backup (image, port, container name, folders),
+except for the ones set by the flags, the env variables or the config file, which take precedence.
+
+The archive is verified against its checksums before touching any file,
+and the restore fails if the folders are not empty, unless --force is set.`,
+	Example: `meow restore ~/backups/default-20240601-120000.tar.gz
+meow restore backup.tar.gz --instance staging --force`,
+	Args: cobra.ExactArgs(1),
+	Run:  executeRestore,
+}
+
+var backupCmdFlags struct {
+	output  string
+	pause   bool
+	force   bool
+	noStart bool
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+	rootCmd.AddCommand(restoreCmd)
+
+	addCatInstanceFlags(backupCmd.Flags())
+	backupCmd.Flags().StringVarP(&backupCmdFlags.output, "output", "o", "", "The backup archive (default is <instance>-<timestamp>.tar.gz in the meow data folder)")
+	backupCmd.Flags().BoolVar(&backupCmdFlags.pause, "pause", false, "Pause the cat container instead of stopping it (default is false)")
+
+	addCatInstanceFlags(restoreCmd.Flags())
+	restoreCmd.Flags().BoolVar(&backupCmdFlags.force, "force", false, "Overwrite the content of the folders which are not empty (default is false)")
+	restoreCmd.Flags().BoolVar(&backupCmdFlags.noStart, "no-start", false, "Do not start the cat after restoring the backup (default is false)")
+}
+
+// executeBackup performs the "backup" logic.
+func executeBackup(cmd *cobra.Command, args []string) {
+	instance, err := resolveCatInstance(cmd)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	output := backupCmdFlags.output
+	if output == "" {
+		folder, err := defaultBackupFolder(instance.Name)
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		output = backupPath(folder, instance.Name, time.Now())
+	}
+
+	_, err = backupInstance(cmd.Context(), instance, output, backupCmdFlags.pause)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+// defaultBackupFolder returns the folder of the backups of the instance in the meow data folder.
+func defaultBackupFolder(instanceName string) (string, error) {
+	return dataDir("backups", instanceName)
+}
+
+// backupPath returns the path of a backup of the instance made now, in the folder.
+func backupPath(folder string, instanceName string, now time.Time) string {
+	return filepath.Join(folder, fmt.Sprintf("%s-%s%s", instanceName, now.Format(backup.TimestampLayout), backup.ArchiveExtension))
+}
+
+// backupInstance archives the instance folders to output, stopping (or pausing) the cat container meanwhile.
+func backupInstance(ctx context.Context, instance catInstance, output string, pause bool) (*backup.Manifest, error) {
+	dockerClient, err := docker.NewDockerClient(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer dockerClient.Close()
+
+	status, err := dockerClient.InspectCatContainer(ctx, instance.ContainerName)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := backupConfig(instance, status)
+	if err != nil {
+		return nil, err
+	}
+
+	resume := func() error { return nil }
+	switch {
+	case status.State == "paused":
+		slog.Debug("The cat container is already paused", slog.String("container", instance.ContainerName))
+	case status.Running && pause:
+		slog.Info("Pausing the cat...", slog.String("container", instance.ContainerName))
+		err = dockerClient.PauseCatContainer(ctx, instance.ContainerName)
+		resume = func() error {
+			slog.Info("Resuming the cat...", slog.String("container", instance.ContainerName))
+			return dockerClient.UnpauseCatContainer(context.WithoutCancel(ctx), instance.ContainerName)
+		}
+	case status.Running:
+		slog.Info("Stopping the cat...", slog.String("container", instance.ContainerName))
+		err = dockerClient.StopCatContainer(ctx, instance.ContainerName)
+		resume = func() error {
+			slog.Info("Starting the cat...", slog.String("container", instance.ContainerName))
+			return dockerClient.StartStoppedCatContainer(context.WithoutCancel(ctx), instance.ContainerName)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Backing up the cat...", slog.String("instance", instance.Name), slog.String("output", output))
+	start := time.Now()
+	manifest, err := backup.Create(output, config)
+	err = errors.Join(err, resume())
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info(
+		"Backup completed",
+		slog.String("archive", output),
+		slog.Int("files", len(manifest.Files)),
+		slog.Duration("elapsed", time.Since(start).Truncate(time.Millisecond)),
+	)
+	return manifest, nil
+}
+
+// backupConfig returns what to back up, preferring the folders and image of the existing container to the instance settings.
+func backupConfig(instance catInstance, status *docker.CatContainerStatus) (backup.Config, error) {
+	err := instance.absFolders()
+	if err != nil {
+		return backup.Config{}, err
+	}
+
+	config := backup.Config{
+		Instance:    instance.Name,
+		Settings:    instance.publicSettings(),
+		Image:       instance.FullImage(),
+		ImageDigest: status.ImageDigest,
+		FolderPaths: map[string]string{
+			"plugins": instance.PluginsFolder,
+			"data":    instance.DataFolder,
+			"static":  instance.StaticFolder,
+		},
+	}
+	if status.Image != "" {
+		config.Image = status.Image
+	}
+	for folder, mountedPath := range map[string]string{"plugins": status.PluginsFolder, "data": status.DataFolder, "static": status.StaticFolder} {
+		if mountedPath != "" {
+			config.FolderPaths[folder] = mountedPath
+		}
+	}
+
+	return config, nil
+}
+
+// executeRestore performs the "restore" logic.
+func executeRestore(cmd *cobra.Command, args []string) {
+	err := runRestore(cmd, args[0])
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+func runRestore(cmd *cobra.Command, archive string) error {
+	ctx := cmd.Context()
+
+	slog.Info("Verifying the backup...", slog.String("archive", archive))
+	manifest, err := backup.Verify(archive)
+	if err != nil {
+		return err
+	}
+	slog.Debug(
+		"Backup verified",
+		slog.String("instance", manifest.Instance),
+		slog.Time("created_at", manifest.CreatedAt),
+		slog.Int("files", len(manifest.Files)),
+	)
+
+	instance, err := restoredInstance(cmd, manifest)
+	if err != nil {
+		return err
+	}
+
+	dockerClient, err := docker.NewDockerClient(nil)
+	if err != nil {
+		return err
+	}
+	defer dockerClient.Close()
+
+	_, running, err := dockerClient.CatContainerExists(ctx, instance.ContainerName)
+	if err != nil {
+		return err
+	}
+	if running {
+		if !backupCmdFlags.force {
+			return fmt.Errorf("the cat container %q is running, stop it with \"meow down\" or use --force", instance.ContainerName)
+		}
+		slog.Info("Stopping the cat...", slog.String("container", instance.ContainerName))
+		err = dockerClient.StopCatContainer(ctx, instance.ContainerName)
+		if err != nil {
+			return err
+		}
+	}
+
+	slog.Info("Restoring the backup...", slog.String("archive", archive), slog.String("instance", instance.Name))
+	_, err = backup.Restore(archive, backup.RestoreConfig{
+		FolderPaths: map[string]string{
+			"plugins": instance.PluginsFolder,
+			"data":    instance.DataFolder,
+			"static":  instance.StaticFolder,
+		},
+		Force: backupCmdFlags.force,
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Backup restored", slog.Int("files", len(manifest.Files)))
+
+	if backupCmdFlags.noStart {
+		return nil
+	}
+
+	err = runUp(ctx, instance)
+	if err != nil {
+		return err
+	}
+
+	if manifest.ImageDigest != "" {
+		status, err := dockerClient.InspectCatContainer(ctx, instance.ContainerName)
+		if err == nil && status.ImageDigest != manifest.ImageDigest {
+			slog.Warn(
+				"The cat image differs from the backed up one",
+				slog.String("backup_digest", manifest.ImageDigest),
+				slog.String("digest", status.ImageDigest),
+			)
+		}
+	}
+
+	return nil
+}
+
+// restoredInstance returns the instance the backup is restored to, recreated from the settings saved in the backup
+// except for the ones configured by the flags, the env variables or the config file.
+func restoredInstance(cmd *cobra.Command, manifest *backup.Manifest) (catInstance, error) {
+	saved := make(map[string]any, len(manifest.Settings))
+	for key, value := range manifest.Settings {
+		setting, exists := findInstanceSetting(key)
+		if !exists || setting.Secret || cast.ToString(value) == "" {
+			continue
+		}
+
+		// the manifest may come from an older or crafted backup
+		parsed, err := setting.Parse(cast.ToString(value))
+		if err != nil {
+			slog.Warn("Ignoring an invalid setting of the backup", slog.String("key", key), slog.String("error", err.Error()))
+			continue
+		}
+		saved[key] = parsed
+	}
+	// the image of the backed up container is more accurate than the configured one
+	if manifest.Image != "" {
+		saved["image"], saved["image_version"] = splitImageReference(manifest.Image)
+	}
+
+	instance, err := resolveCatInstanceWithFallback(cmd, saved)
+	if err != nil {
+		return catInstance{}, err
+	}
+	err = instance.absFolders()
+	if err != nil {
+		return catInstance{}, err
+	}
+
+	settings := instance.publicSettings()
+	for _, setting := range instanceSettings {
+		value, exists := saved[setting.Key]
+		if exists && cast.ToString(settings[setting.Key]) != cast.ToString(value) {
+			slog.Warn(
+				"The configured instance setting differs from the backed up one",
+				slog.String("key", setting.Key),
+				slog.Any("value", settings[setting.Key]),
+				slog.Any("backup_value", value),
+			)
+		}
+	}
+
+	return instance, nil
+}
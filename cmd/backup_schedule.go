@@ -0,0 +1,285 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/saniales/meow-cli/pkg/backup"
+)
+
+var backupScheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Backs up the cat periodically",
+	Long: `Backs up the active instance on an interval, running in the foreground until interrupted,
+and prunes the old archives keeping the most recent ones (--keep) and removing the
+ones older than --max-age. The most recent archive is never pruned.
+
+Each run is logged, so with --json the logs can be shipped to a log collector.
+
+With --once a single backup is made and pruned, which is what the systemd timer
+or cron entry printed by --emit runs, for the machines where a long-lived process is not welcome.`,
+	Example: `meow backup schedule --every 6h --keep 8 --max-age 168h
+meow backup schedule --instance staging --every 24h --json >> backups.log
+meow backup schedule --every 24h --keep 7 --emit systemd`,
+	Args: cobra.NoArgs,
+	Run:  executeBackupSchedule,
+}
+
+var backupScheduleCmdFlags struct {
+	every  time.Duration
+	keep   int
+	maxAge time.Duration
+	dir    string
+	pause  bool
+	once   bool
+	emit   string
+}
+
+func init() {
+	backupCmd.AddCommand(backupScheduleCmd)
+
+	addCatInstanceFlags(backupScheduleCmd.Flags())
+	backupScheduleCmd.Flags().DurationVar(&backupScheduleCmdFlags.every, "every", 24*time.Hour, "Interval between the backups")
+	backupScheduleCmd.Flags().IntVar(&backupScheduleCmdFlags.keep, "keep", 7, "Number of most recent archives kept, 0 keeps all of them")
+	backupScheduleCmd.Flags().DurationVar(&backupScheduleCmdFlags.maxAge, "max-age", 0, "Remove the archives older than this, 0 disables the check (default is 0)")
+	backupScheduleCmd.Flags().StringVar(&backupScheduleCmdFlags.dir, "dir", "", "Folder of the archives (default is the backups folder of the instance in the meow data folder)")
+	backupScheduleCmd.Flags().BoolVar(&backupScheduleCmdFlags.pause, "pause", false, "Pause the cat container instead of stopping it (default is false)")
+	backupScheduleCmd.Flags().BoolVar(&backupScheduleCmdFlags.once, "once", false, "Make a single backup, prune the old ones and exit (default is false)")
+	backupScheduleCmd.Flags().StringVar(&backupScheduleCmdFlags.emit, "emit", "", "Print a systemd timer or a cron entry running the backups instead, one of systemd, cron (default is none)")
+}
+
+// executeBackupSchedule performs the "backup schedule" logic.
+func executeBackupSchedule(cmd *cobra.Command, args []string) {
+	instance, err := resolveCatInstance(cmd)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	err = runBackupSchedule(cmd.Context(), instance)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+func runBackupSchedule(ctx context.Context, instance catInstance) error {
+	if backupScheduleCmdFlags.every <= 0 {
+		return fmt.Errorf("the backup interval must be positive")
+	}
+	if backupScheduleCmdFlags.keep < 0 || backupScheduleCmdFlags.maxAge < 0 {
+		return fmt.Errorf("--keep and --max-age cannot be negative")
+	}
+
+	switch backupScheduleCmdFlags.emit {
+	case "":
+	case "systemd":
+		return emitBackupSystemdTimer(instance)
+	case "cron":
+		return emitBackupCronEntry(instance)
+	default:
+		return fmt.Errorf("unknown --emit value %q, expected systemd or cron", backupScheduleCmdFlags.emit)
+	}
+
+	folder := backupScheduleCmdFlags.dir
+	if folder == "" {
+		var err error
+		folder, err = defaultBackupFolder(instance.Name)
+		if err != nil {
+			return err
+		}
+	} else {
+		err := os.MkdirAll(folder, 0o700)
+		if err != nil {
+			return err
+		}
+	}
+
+	if backupScheduleCmdFlags.once {
+		return runScheduledBackup(ctx, instance, folder)
+	}
+
+	slog.Info(
+		"Backup schedule started",
+		slog.String("instance", instance.Name),
+		slog.String("folder", folder),
+		slog.Duration("every", backupScheduleCmdFlags.every),
+		slog.Int("keep", backupScheduleCmdFlags.keep),
+		slog.Duration("max_age", backupScheduleCmdFlags.maxAge),
+	)
+
+	ticker := time.NewTicker(backupScheduleCmdFlags.every)
+	defer ticker.Stop()
+	nextRun := time.Now().Add(backupScheduleCmdFlags.every)
+	for {
+		// a failed run is logged and retried at the next tick, the schedule keeps going
+		err := runScheduledBackup(ctx, instance, folder)
+		if err != nil && ctx.Err() == nil {
+			slog.Error("Scheduled backup failed", slog.String("instance", instance.Name), slog.String("error", err.Error()))
+		}
+		slog.Info("Next backup scheduled", slog.Time("at", nextRun.Truncate(time.Second)))
+
+		select {
+		case <-ctx.Done():
+			slog.Info("Backup schedule stopped", slog.String("instance", instance.Name))
+			return nil
+		case tick := <-ticker.C:
+			nextRun = tick.Add(backupScheduleCmdFlags.every)
+		}
+	}
+}
+
+// runScheduledBackup backs up the instance in the folder, then prunes the old archives.
+func runScheduledBackup(ctx context.Context, instance catInstance, folder string) error {
+	now := time.Now()
+	_, err := backupInstance(ctx, instance, backupPath(folder, instance.Name, now), backupScheduleCmdFlags.pause)
+	if err != nil {
+		return err
+	}
+
+	pruned, err := backup.Prune(folder, backup.PruneConfig{
+		Prefix: instance.Name + "-",
+		Keep:   backupScheduleCmdFlags.keep,
+		MaxAge: backupScheduleCmdFlags.maxAge,
+	}, now)
+	for _, archive := range pruned {
+		slog.Info("Old backup pruned", slog.String("archive", archive))
+	}
+
+	return err
+}
+
+// scheduledBackupCommand returns the command line making a single scheduled backup with the same settings.
+func scheduledBackupCommand(instance catInstance) (string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{executable, "backup", "schedule", "--once", "--instance", instance.Name, "--keep", fmt.Sprint(backupScheduleCmdFlags.keep)}
+	if globalFlags.configFile != "" {
+		args = append(args, "--config", globalFlags.configFile)
+	}
+	if backupScheduleCmdFlags.maxAge > 0 {
+		args = append(args, "--max-age", backupScheduleCmdFlags.maxAge.String())
+	}
+	if backupScheduleCmdFlags.dir != "" {
+		// the timers do not run in the current folder
+		dir, err := filepath.Abs(backupScheduleCmdFlags.dir)
+		if err != nil {
+			return "", err
+		}
+		args = append(args, "--dir", dir)
+	}
+	if backupScheduleCmdFlags.pause {
+		args = append(args, "--pause")
+	}
+	if globalFlags.json {
+		args = append(args, "--json")
+	}
+
+	for index, arg := range args {
+		args[index] = shellQuote(arg)
+	}
+
+	return strings.Join(args, " "), nil
+}
+
+// emitBackupSystemdTimer prints the systemd user service and timer running the scheduled backups.
+func emitBackupSystemdTimer(instance catInstance) error {
+	command, err := scheduledBackupCommand(instance)
+	if err != nil {
+		return err
+	}
+	unit := "meow-backup-" + instance.Name
+
+	fmt.Printf(`# ~/.config/systemd/user/%[1]s.service
+[Unit]
+Description=Backup of the %[2]s cat instance
+
+[Service]
+Type=oneshot
+ExecStart=%[3]s
+
+# ~/.config/systemd/user/%[1]s.timer
+[Unit]
+Description=Periodic backups of the %[2]s cat instance
+
+[Timer]
+OnBootSec=15min
+OnUnitActiveSec=%[4]ds
+
+[Install]
+WantedBy=timers.target
+
+# enable it with: systemctl --user daemon-reload && systemctl --user enable --now %[1]s.timer
+`, unit, instance.Name, command, int64(backupScheduleCmdFlags.every.Seconds()))
+
+	return nil
+}
+
+// emitBackupCronEntry prints the crontab entry running the scheduled backups.
+func emitBackupCronEntry(instance catInstance) error {
+	schedule, err := cronSchedule(backupScheduleCmdFlags.every)
+	if err != nil {
+		return err
+	}
+	command, err := scheduledBackupCommand(instance)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("# add it to the crontab with: crontab -e\n%s %s\n", schedule, command)
+
+	return nil
+}
+
+// cronSchedule converts the interval to a cron schedule, if it can be expressed as one.
+func cronSchedule(every time.Duration) (string, error) {
+	switch {
+	case every%(24*time.Hour) == 0 && every <= 24*time.Hour:
+		return "0 0 * * *", nil
+	case every%time.Hour == 0 && 24%int(every.Hours()) == 0:
+		return fmt.Sprintf("0 */%d * * *", int(every.Hours())), nil
+	case every%time.Minute == 0 && every < time.Hour && 60%int(every.Minutes()) == 0:
+		return fmt.Sprintf("*/%d * * * *", int(every.Minutes())), nil
+	default:
+		return "", fmt.Errorf("the interval %s cannot be expressed as a cron schedule, use --emit systemd instead", every)
+	}
+}
+
+// shellSafeArg matches the arguments which do not need quoting.
+var shellSafeArg = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// shellQuote quotes the argument for a shell command line, if needed.
+func shellQuote(arg string) string {
+	if shellSafeArg.MatchString(arg) {
+		return arg
+	}
+
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
@@ -0,0 +1,96 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/saniales/meow-cli/pkg/backup"
+)
+
+func TestRestoredInstance(t *testing.T) {
+	manifest := &backup.Manifest{
+		Instance: "default",
+		Image:    "ghcr.io/cheshire-cat-ai/core:1.7.1",
+		Settings: map[string]any{
+			"image":          "ghcr.io/cheshire-cat-ai/core",
+			"image_version":  "latest",
+			"port":           float64(1866),
+			"container_name": "cat-production",
+			"plugins_folder": "/srv/cat/plugins",
+			"data_folder":    "/srv/cat/data",
+			"static_folder":  "/srv/cat/static",
+			"api_url":        "",
+			"user_id":        "",
+		},
+	}
+
+	tests := []struct {
+		name   string
+		config string
+		args   []string
+		want   catInstance
+	}{
+		{
+			name: "from the backup",
+			want: catInstance{
+				Name: "default", Image: "ghcr.io/cheshire-cat-ai/core", ImageVersion: "1.7.1", Port: 1866, ContainerName: "cat-production",
+				PluginsFolder: "/srv/cat/plugins", DataFolder: "/srv/cat/data", StaticFolder: "/srv/cat/static",
+			},
+		},
+		{
+			name:   "config file and flags take precedence",
+			config: "port: 1900\ninstances:\n  default:\n    data_folder: /data\n",
+			args:   []string{"--name", "cat-staging", "--image-version", "1.7.2"},
+			want: catInstance{
+				Name: "default", Image: "ghcr.io/cheshire-cat-ai/core", ImageVersion: "1.7.2", Port: 1900, ContainerName: "cat-staging",
+				PluginsFolder: "/srv/cat/plugins", DataFolder: "/data", StaticFolder: "/srv/cat/static",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			viper.SetConfigType("yaml")
+			err := viper.ReadConfig(strings.NewReader(test.config))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer viper.Reset()
+
+			cmd := &cobra.Command{}
+			addCatInstanceFlags(cmd.Flags())
+			err = cmd.ParseFlags(test.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := restoredInstance(cmd, manifest)
+			if err != nil {
+				t.Fatalf("restoredInstance() error = %v", err)
+			}
+			if got != test.want {
+				t.Errorf("restoredInstance() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"net/http"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/cobra"
+
+	"github.com/saniales/meow-cli/pkg/providers/cat"
+)
+
+// resolveCatClient creates a client for the cat API of the active instance of the command.
+func resolveCatClient(cmd *cobra.Command) (*cat.Client, error) {
+	instance, err := resolveCatInstance(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return newCatClient(instance)
+}
+
+// newCatClient creates a client for the cat API of the instance.
+func newCatClient(instance catInstance) (*cat.Client, error) {
+	return cat.NewClient(new(http.Client), cat.ClientConfig{
+		BaseURL: instance.BaseURL(),
+		APIKey:  instance.APIKey,
+		UserID:  instance.UserID,
+	})
+}
+
+// resolveRegistryClient creates a client for the plugin registry configured with registry_url,
+// returning nil if the registry must be queried through the cat.
+func resolveRegistryClient(cmd *cobra.Command) (*cat.RegistryClient, error) {
+	value, _ := resolveInstanceSetting(cmd, activeInstanceName(), registryURLSetting)
+	registryURL := cast.ToString(value)
+	if registryURL == "" {
+		return nil, nil
+	}
+
+	return cat.NewRegistryClient(new(http.Client), registryURL)
+}
@@ -0,0 +1,509 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/saniales/meow-cli/pkg/providers/cat"
+)
+
+// chatPromptHelp describes the commands available in the chat prompt.
+const chatPromptHelp = `Prompt commands:
+  /why          shows the why metadata (recalled memories, tools used) of the last reply
+  /why on|off   toggles showing the why metadata after each reply
+  /history      lists the previous inputs
+  !!            sends again the last input
+  !<n>          sends again the input number n of the history
+  /help         shows this help
+  /quit         exits the chat (or Ctrl+D)
+
+Start the input with \ to send a message beginning with / or ! as it is, e.g. \/etc/hosts is empty.`
+
+var chatCmd = &cobra.Command{
+	Use:   "chat",
+	Short: "Chats with the cat in an interactive prompt",
+	Long: `Chats with the cat in an interactive prompt, over the cat WebSocket.
+
+The replies are streamed as they are generated. End a line with \ to continue
+the message on the next line. The connection is restored automatically
+if the cat restarts.
+
+` + chatPromptHelp,
+	Example: "meow chat --why",
+	Args:    cobra.NoArgs,
+	Run:     executeChat,
+}
+
+var chatCmdFlags struct {
+	why              bool
+	historySize      int
+	reconnectTimeout time.Duration
+}
+
+func init() {
+	rootCmd.AddCommand(chatCmd)
+
+	addCatAPIFlags(chatCmd.Flags())
+	chatCmd.Flags().BoolVar(&chatCmdFlags.why, "why", false, "Show the why metadata after each reply (default is false)")
+	chatCmd.Flags().IntVar(&chatCmdFlags.historySize, "history-size", 1000, "Maximum number of inputs kept in the local history")
+	chatCmd.Flags().DurationVar(&chatCmdFlags.reconnectTimeout, "reconnect-timeout", 2*time.Minute, "Maximum time to wait for the cat when the connection is lost")
+}
+
+// executeChat performs the "chat" logic.
+func executeChat(cmd *cobra.Command, args []string) {
+	instance, err := resolveCatInstance(cmd)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	err = runChat(cmd.Context(), instance)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+func runChat(ctx context.Context, instance catInstance) error {
+	if chatCmdFlags.historySize < 0 {
+		return fmt.Errorf("--history-size cannot be negative")
+	}
+
+	catClient, err := newCatClient(instance)
+	if err != nil {
+		return err
+	}
+
+	history, err := loadInputHistory(instance.Name, chatCmdFlags.historySize)
+	if err != nil {
+		return err
+	}
+
+	session := &chatSession{
+		catClient: catClient,
+		history:   history,
+		showWhy:   chatCmdFlags.why,
+	}
+	defer session.disconnect()
+	stop := context.AfterFunc(ctx, session.disconnect)
+	defer stop()
+
+	err = session.connect(ctx)
+	if err != nil {
+		return err
+	}
+	slog.Info("Connected to the cat, type /help for the available commands", slog.String("url", catClient.BaseURL().String()))
+
+	lines := readLines(os.Stdin)
+	for {
+		input, ok := readChatInput(ctx, lines)
+		if !ok {
+			return ctx.Err()
+		}
+
+		input = strings.TrimSpace(input)
+		if input == "" {
+			continue
+		}
+
+		if message, escaped := unescapeChatInput(input); escaped {
+			input = message
+		} else if strings.HasPrefix(input, "/") {
+			if session.runCommand(input) {
+				return nil
+			}
+			continue
+		} else if strings.HasPrefix(input, "!") {
+			input, err = history.Recall(input)
+			if err != nil {
+				slog.Error(err.Error())
+				continue
+			}
+			fmt.Println(input)
+		}
+
+		err = history.Add(input)
+		if err != nil {
+			slog.Warn("Cannot save the chat history", slog.String("error", err.Error()))
+		}
+
+		err = session.ask(ctx, input)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// unescapeChatInput returns the message of an input starting with \/ or \!,
+// which is sent as it is instead of being taken as a prompt command or a history recall.
+func unescapeChatInput(input string) (string, bool) {
+	message, found := strings.CutPrefix(input, `\`)
+	if !found || !strings.HasPrefix(message, "/") && !strings.HasPrefix(message, "!") {
+		return input, false
+	}
+
+	return message, true
+}
+
+// readLines reads the lines from the reader in background, closing the channel at EOF.
+func readLines(reader io.Reader) <-chan string {
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	return lines
+}
+
+// readChatInput prompts for a message, joining the lines ending with a backslash.
+func readChatInput(ctx context.Context, lines <-chan string) (string, bool) {
+	prompt := "you> "
+	var input strings.Builder
+	for {
+		if !globalFlags.json {
+			fmt.Print(prompt)
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", false
+		case line, ok := <-lines:
+			if !ok {
+				if !globalFlags.json {
+					fmt.Println()
+				}
+				return input.String(), input.Len() > 0
+			}
+
+			if strings.HasSuffix(line, "\\") {
+				input.WriteString(strings.TrimSuffix(line, "\\"))
+				input.WriteString("\n")
+				prompt = "...> "
+				continue
+			}
+
+			input.WriteString(line)
+			return input.String(), true
+		}
+	}
+}
+
+// chatSession holds the state of the chat with the cat.
+type chatSession struct {
+	catClient *cat.Client
+	history   *inputHistory
+	showWhy   bool
+	lastReply *cat.ChatMessage
+
+	mutex      sync.Mutex
+	connection *cat.ChatConnection
+}
+
+// connect opens the WebSocket connection, retrying until the reconnect timeout expires.
+func (session *chatSession) connect(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, chatCmdFlags.reconnectTimeout)
+	defer cancel()
+
+	backoff := 500 * time.Millisecond
+	for {
+		connection, err := session.catClient.DialChat(ctx)
+		if err == nil {
+			session.mutex.Lock()
+			session.connection = connection
+			session.mutex.Unlock()
+			return nil
+		}
+
+		if errors.Is(err, cat.ErrUnauthorized) {
+			return err
+		}
+		slog.Debug("Cannot connect to the cat", slog.String("error", err.Error()), slog.Duration("retry_in", backoff))
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("cannot connect to the cat: %w", err)
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, 10*time.Second)
+	}
+}
+
+// disconnect closes the WebSocket connection, if open.
+func (session *chatSession) disconnect() {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	if session.connection != nil {
+		session.connection.Close()
+		session.connection = nil
+	}
+}
+
+// ask sends the message to the cat and prints the reply,
+// reconnecting and sending the message again if the connection is lost.
+func (session *chatSession) ask(ctx context.Context, input string) error {
+	for {
+		session.mutex.Lock()
+		connection := session.connection
+		session.mutex.Unlock()
+
+		if connection != nil {
+			err := connection.Send(input)
+			if err == nil {
+				err = session.printReply(connection)
+			}
+			if err == nil || ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			slog.Warn("Connection to the cat lost, reconnecting...", slog.String("error", err.Error()))
+			session.disconnect()
+		}
+
+		err := session.connect(ctx)
+		if err != nil {
+			return err
+		}
+		slog.Info("Reconnected to the cat")
+	}
+}
+
+// printReply prints the messages sent by the cat until the final reply.
+func (session *chatSession) printReply(connection *cat.ChatConnection) error {
+	streamed := false
+	for {
+		message, err := connection.Receive()
+		if err != nil {
+			if streamed {
+				fmt.Println()
+			}
+			return err
+		}
+
+		if globalFlags.json {
+			err = printJSON(message)
+			if err != nil {
+				return err
+			}
+		}
+
+		switch message.Type {
+		case cat.MessageTypeChatToken:
+			if !globalFlags.json {
+				if !streamed {
+					fmt.Print("cat> ")
+				}
+				fmt.Print(message.Message())
+			}
+			streamed = true
+		case cat.MessageTypeNotification:
+			slog.Info(message.Message())
+		case cat.MessageTypeError:
+			slog.Error(fmt.Sprintf("%s: %s", message.Name, message.Description))
+			return nil
+		case cat.MessageTypeChat:
+			session.lastReply = message
+			if !globalFlags.json {
+				if streamed {
+					fmt.Println()
+				} else {
+					fmt.Printf("cat> %s\n", message.Message())
+				}
+			}
+			if session.showWhy {
+				session.printWhy()
+			}
+			return nil
+		}
+	}
+}
+
+// runCommand executes a prompt command, returning true if the chat must end.
+func (session *chatSession) runCommand(input string) bool {
+	command, argument, _ := strings.Cut(input, " ")
+	argument = strings.TrimSpace(argument)
+
+	switch command {
+	case "/quit", "/exit":
+		return true
+	case "/help":
+		fmt.Println(chatPromptHelp)
+	case "/history":
+		for index, entry := range session.history.entries {
+			fmt.Printf("%5d  %s\n", index+1, strings.ReplaceAll(entry, "\n", "\n       "))
+		}
+	case "/why":
+		switch argument {
+		case "on":
+			session.showWhy = true
+			slog.Info("The why metadata will be shown after each reply")
+		case "off":
+			session.showWhy = false
+			slog.Info("The why metadata will not be shown after each reply")
+		case "":
+			session.printWhy()
+		default:
+			slog.Error("Usage: /why [on|off]")
+		}
+	default:
+		slog.Error(fmt.Sprintf("Unknown command %s, type /help for the available commands", command))
+	}
+
+	return false
+}
+
+// printWhy prints the recalled memories and tools used for the last reply.
+func (session *chatSession) printWhy() {
+	if session.lastReply == nil {
+		slog.Info("No reply yet")
+		return
+	}
+
+	if globalFlags.json {
+		printJSON(session.lastReply.Why)
+		return
+	}
+
+	why, err := session.lastReply.ParseWhy()
+	if err != nil {
+		slog.Error(err.Error())
+		return
+	}
+	if why == nil {
+		fmt.Println("No why metadata for the last reply")
+		return
+	}
+
+	tools := why.ToolsUsed()
+	if len(tools) == 0 {
+		fmt.Println("Tools used: none")
+	} else {
+		fmt.Printf("Tools used: %s\n", strings.Join(tools, ", "))
+	}
+
+	fmt.Println("Recalled memories:")
+	table := newTableWriter(os.Stdout)
+	for _, collection := range []string{"episodic", "declarative", "procedural"} {
+		for _, memory := range why.Memory[collection] {
+			fmt.Fprintf(table, "  %s\t%.3f\t%v\t%s\n", collection, memory.Score, memory.Metadata["source"], truncate(memory.PageContent, 80))
+		}
+	}
+	table.Flush()
+}
+
+// inputHistory is the local history of the chat inputs, stored as JSON lines.
+type inputHistory struct {
+	path    string
+	maxSize int
+	entries []string
+}
+
+// loadInputHistory loads the input history of the instance.
+func loadInputHistory(instanceName string, maxSize int) (*inputHistory, error) {
+	dir, err := dataDir("history")
+	if err != nil {
+		return nil, err
+	}
+
+	history := &inputHistory{
+		path:    filepath.Join(dir, instanceName+".jsonl"),
+		maxSize: maxSize,
+	}
+
+	file, err := os.Open(history.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry string
+		if json.Unmarshal(scanner.Bytes(), &entry) == nil {
+			history.entries = append(history.entries, entry)
+		}
+	}
+	if len(history.entries) > maxSize {
+		history.entries = history.entries[len(history.entries)-maxSize:]
+	}
+
+	return history, scanner.Err()
+}
+
+// Add appends the input to the history, trimming the oldest entries.
+func (history *inputHistory) Add(input string) error {
+	history.entries = append(history.entries, input)
+	if len(history.entries) > history.maxSize {
+		history.entries = history.entries[len(history.entries)-history.maxSize:]
+	}
+
+	var content strings.Builder
+	for _, entry := range history.entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		content.Write(line)
+		content.WriteString("\n")
+	}
+
+	return os.WriteFile(history.path, []byte(content.String()), 0o600)
+}
+
+// Recall returns the history entry referenced by "!!" (last) or "!<n>".
+func (history *inputHistory) Recall(reference string) (string, error) {
+	if len(history.entries) == 0 {
+		return "", fmt.Errorf("the history is empty")
+	}
+
+	if reference == "!!" {
+		return history.entries[len(history.entries)-1], nil
+	}
+
+	index, err := strconv.Atoi(strings.TrimPrefix(reference, "!"))
+	if err != nil || index < 1 || index > len(history.entries) {
+		return "", fmt.Errorf("%s is not in the history, type /history to list it", reference)
+	}
+
+	return history.entries[index-1], nil
+}
@@ -0,0 +1,45 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import "testing"
+
+func TestUnescapeChatInput(t *testing.T) {
+	tests := []struct {
+		input       string
+		want        string
+		wantEscaped bool
+	}{
+		{input: `\/etc/hosts is empty`, want: "/etc/hosts is empty", wantEscaped: true},
+		{input: `\!important`, want: "!important", wantEscaped: true},
+		{input: `\\/x`, want: `\\/x`},
+		{input: `\n is a newline`, want: `\n is a newline`},
+		{input: "/why", want: "/why"},
+		{input: "!!", want: "!!"},
+		{input: "hello", want: "hello"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			got, escaped := unescapeChatInput(test.input)
+			if got != test.want || escaped != test.wantEscaped {
+				t.Errorf("unescapeChatInput(%q) = %q, %v, want %q, %v", test.input, got, escaped, test.want, test.wantEscaped)
+			}
+		})
+	}
+}
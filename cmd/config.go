@@ -0,0 +1,324 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Views and edits the meow configuration",
+	Long: `Views and edits the meow configuration.
+
+Instance settings (e.g. port) refer to the active instance profile,
+use the full key (e.g. instances.staging.port) to target another instance.`,
+	Example: "meow config set port 1866",
+}
+
+var configGetCmd = &cobra.Command{
+	Use:     "get <key>",
+	Short:   "Prints the effective value of a config key",
+	Long:    `Prints the effective value of a config key`,
+	Example: "meow config get instances.staging.api_url",
+	Args:    cobra.ExactArgs(1),
+	Run:     executeConfigGet,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:     "set <key> <value>",
+	Short:   "Sets a config key in the config file",
+	Long:    `Sets a config key in the config file, validating the value`,
+	Example: "meow config set port 1866",
+	Args:    cobra.ExactArgs(2),
+	Run:     executeConfigSet,
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:     "unset <key>",
+	Short:   "Removes a config key from the config file",
+	Long:    `Removes a config key from the config file, restoring its default value`,
+	Example: "meow config unset port",
+	Args:    cobra.ExactArgs(1),
+	Run:     executeConfigUnset,
+}
+
+var configListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "Lists the effective configuration of the active instance",
+	Long:    `Lists the effective configuration of the active instance, showing where each value comes from (flag, env, file, default)`,
+	Example: "meow config list --instance staging",
+	Args:    cobra.NoArgs,
+	Run:     executeConfigList,
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Prints the path of the config file",
+	Long:  `Prints the path of the config file`,
+	Args:  cobra.NoArgs,
+	Run:   executeConfigPath,
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Opens the config file in $EDITOR",
+	Long:  `Opens the config file in $EDITOR, validating it once closed`,
+	Args:  cobra.NoArgs,
+	Run:   executeConfigEdit,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validates the config file",
+	Long:  `Validates the config file, rejecting unknown keys and invalid values`,
+	Args:  cobra.NoArgs,
+	Run:   executeConfigValidate,
+}
+
+var configListCmdFlags struct {
+	showSecrets bool
+}
+
+// configValueOutput represents an effective config value.
+type configValueOutput struct {
+	Key    string `json:"key"`
+	Value  any    `json:"value"`
+	Source string `json:"source"`
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configPathCmd)
+	configCmd.AddCommand(configEditCmd)
+	configCmd.AddCommand(configValidateCmd)
+
+	addCatInstanceFlags(configListCmd.Flags())
+	addCatAPIFlags(configListCmd.Flags())
+	configListCmd.Flags().String(registryURLSetting.Flag, "", "The plugin registry URL queried directly (default is through the cat)")
+	configListCmd.Flags().BoolVar(&configListCmdFlags.showSecrets, "show-secrets", false, "Show secret values instead of masking them (default is false)")
+}
+
+// resolveConfigValue returns the effective value of the referenced key and where it comes from,
+// considering the flags of cmd (if not nil).
+func resolveConfigValue(cmd *cobra.Command, ref configKeyRef) configValueOutput {
+	if ref.Instance == "" && ref.FileKey == "current_instance" {
+		name, source := resolveActiveInstance()
+		return configValueOutput{Key: ref.FileKey, Value: name, Source: source}
+	}
+
+	value, source := resolveInstanceSetting(cmd, ref.Instance, ref.Setting)
+	return configValueOutput{Key: ref.FileKey, Value: value, Source: source}
+}
+
+// executeConfigGet performs the "config get" logic.
+func executeConfigGet(cmd *cobra.Command, args []string) {
+	ref, err := parseConfigKey(args[0])
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	value := resolveConfigValue(nil, ref)
+	if globalFlags.json {
+		err = printJSON(value)
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Println(cast.ToString(value.Value))
+}
+
+// executeConfigSet performs the "config set" logic.
+func executeConfigSet(cmd *cobra.Command, args []string) {
+	ref, err := parseConfigKey(args[0])
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	value, err := ref.Setting.Parse(args[1])
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: %s", ref.FileKey, err))
+		os.Exit(1)
+	}
+
+	if ref.FileKey == "current_instance" && !instanceExists(args[1]) {
+		slog.Error(ErrInstanceNotDefined(args[1]).Error())
+		os.Exit(1)
+	}
+
+	err = updateConfigFile(func(settings map[string]any) error {
+		setNestedValue(settings, ref.FileKey, value)
+		return nil
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+	slog.Info("Config updated", slog.String("key", ref.FileKey))
+}
+
+// executeConfigUnset performs the "config unset" logic.
+func executeConfigUnset(cmd *cobra.Command, args []string) {
+	ref, err := parseConfigKey(args[0])
+	if err != nil {
+		// unknown keys can still be removed, to fix an invalid config file
+		ref = configKeyRef{FileKey: strings.ToLower(args[0])}
+	}
+
+	err = updateConfigFile(func(settings map[string]any) error {
+		if !deleteNestedValue(settings, ref.FileKey) {
+			return fmt.Errorf("%s is not set in the config file", ref.FileKey)
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+	slog.Info("Config updated", slog.String("key", ref.FileKey))
+}
+
+// executeConfigList performs the "config list" logic.
+func executeConfigList(cmd *cobra.Command, args []string) {
+	instance := activeInstanceName()
+
+	var values []configValueOutput
+	for _, setting := range globalSettings {
+		values = append(values, resolveConfigValue(cmd, configKeyRef{FileKey: setting.Key, Setting: setting}))
+	}
+	for _, setting := range instanceSettings {
+		value := resolveConfigValue(cmd, configKeyRef{
+			FileKey:  fmt.Sprintf("instances.%s.%s", instance, setting.Key),
+			Instance: instance,
+			Setting:  setting,
+		})
+		if setting.Secret && !configListCmdFlags.showSecrets && cast.ToString(value.Value) != "" {
+			value.Value = "********"
+		}
+		values = append(values, value)
+	}
+
+	if globalFlags.json {
+		err := printJSON(values)
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	table := newTableWriter(os.Stdout)
+	defer table.Flush()
+
+	fmt.Fprintln(table, "KEY\tVALUE\tSOURCE")
+	for _, value := range values {
+		fmt.Fprintf(table, "%s\t%v\t%s\n", value.Key, value.Value, value.Source)
+	}
+}
+
+// executeConfigPath performs the "config path" logic.
+func executeConfigPath(cmd *cobra.Command, args []string) {
+	path, err := configFilePath()
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	fmt.Println(path)
+}
+
+// executeConfigEdit performs the "config edit" logic.
+func executeConfigEdit(cmd *cobra.Command, args []string) {
+	path, err := configFilePath()
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	_, err = os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		err = os.WriteFile(path, nil, 0o600)
+	}
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	err = openEditor(path)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	executeConfigValidate(cmd, args)
+}
+
+// executeConfigValidate performs the "config validate" logic.
+func executeConfigValidate(cmd *cobra.Command, args []string) {
+	settings, err := readConfigFile()
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	problems := validateConfigSettings(settings)
+	if globalFlags.json {
+		messages := make([]string, 0, len(problems))
+		for _, problem := range problems {
+			messages = append(messages, problem.Error())
+		}
+
+		err = printJSON(struct {
+			Valid    bool     `json:"valid"`
+			Problems []string `json:"problems"`
+		}{
+			Valid:    len(problems) == 0,
+			Problems: messages,
+		})
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+	} else {
+		for _, problem := range problems {
+			slog.Error(problem.Error())
+		}
+	}
+
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+	slog.Info("The config file is valid")
+}
@@ -0,0 +1,148 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// configFilePath returns the path of the config file in use,
+// or the default one ($HOME/.meow-cli.yaml) if none has been found.
+func configFilePath() (string, error) {
+	if path := viper.ConfigFileUsed(); path != "" {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".meow-cli.yaml"), nil
+}
+
+// readConfigFile returns the settings stored in the config file only,
+// without defaults, env variables and flags.
+func readConfigFile() (map[string]any, error) {
+	path, err := configFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	fileConfig := viper.New()
+	fileConfig.SetConfigFile(path)
+	if filepath.Ext(path) == "" {
+		fileConfig.SetConfigType("yaml")
+	}
+
+	_, err = os.Stat(path)
+	if os.IsNotExist(err) {
+		return map[string]any{}, nil
+	}
+
+	err = fileConfig.ReadInConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return fileConfig.AllSettings(), nil
+}
+
+// updateConfigFile applies the update to the settings stored in the config file and writes them back,
+// creating the file if missing. The global config is reloaded afterwards.
+func updateConfigFile(update func(settings map[string]any) error) error {
+	settings, err := readConfigFile()
+	if err != nil {
+		return err
+	}
+
+	err = update(settings)
+	if err != nil {
+		return err
+	}
+
+	path, err := configFilePath()
+	if err != nil {
+		return err
+	}
+
+	fileConfig := viper.New()
+	if filepath.Ext(path) == "" {
+		fileConfig.SetConfigType("yaml")
+	}
+	err = fileConfig.MergeConfigMap(settings)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	err = fileConfig.WriteConfigAs(path)
+	if err != nil {
+		return err
+	}
+
+	viper.SetConfigFile(path)
+	return viper.ReadInConfig()
+}
+
+// setNestedValue sets the value at the dot separated key, creating the intermediate maps.
+func setNestedValue(settings map[string]any, key string, value any) {
+	parts := strings.Split(strings.ToLower(key), ".")
+	current := settings
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			current[part] = next
+		}
+		current = next
+	}
+
+	current[parts[len(parts)-1]] = value
+}
+
+// deleteNestedValue removes the value at the dot separated key, reporting whether it existed.
+// Intermediate maps left empty are removed as well.
+func deleteNestedValue(settings map[string]any, key string) bool {
+	parts := strings.Split(strings.ToLower(key), ".")
+	if len(parts) == 1 {
+		_, exists := settings[parts[0]]
+		delete(settings, parts[0])
+		return exists
+	}
+
+	next, ok := settings[parts[0]].(map[string]any)
+	if !ok {
+		return false
+	}
+
+	deleted := deleteNestedValue(next, strings.Join(parts[1:], "."))
+	if len(next) == 0 {
+		delete(settings, parts[0])
+	}
+
+	return deleted
+}
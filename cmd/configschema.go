@@ -0,0 +1,231 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// configValueParser validates a raw config value, returning it with the proper type.
+type configValueParser func(raw string) (any, error)
+
+// globalSettings lists the settings not related to a specific instance profile.
+var globalSettings = []configSetting{
+	{Key: "current_instance", Default: defaultInstanceName, Description: "The active cat instance profile", Parse: parseInstanceName},
+	registryURLSetting,
+}
+
+// registryURLSetting is the plugin registry URL, when empty the registry is queried through the cat.
+var registryURLSetting = configSetting{
+	Key:         "registry_url",
+	Flag:        "registry-url",
+	Default:     "",
+	Description: "The plugin registry URL queried directly instead of through the cat",
+	Parse:       parseHTTPURL,
+}
+
+var (
+	containerNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+	instanceNameRegexp  = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+)
+
+func parseString(raw string) (any, error) {
+	return raw, nil
+}
+
+func parseNonEmptyString(raw string) (any, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, fmt.Errorf("value cannot be empty")
+	}
+
+	return raw, nil
+}
+
+func parsePort(raw string) (any, error) {
+	port, err := cast.ToIntE(strings.TrimSpace(raw))
+	if err != nil || port <= 0 || port > 65535 {
+		return nil, fmt.Errorf("%q is not a valid port number (1-65535)", raw)
+	}
+
+	return port, nil
+}
+
+func parseContainerName(raw string) (any, error) {
+	if !containerNameRegexp.MatchString(raw) {
+		return nil, fmt.Errorf("%q is not a valid container name", raw)
+	}
+
+	return raw, nil
+}
+
+func parseInstanceName(raw string) (any, error) {
+	if !instanceNameRegexp.MatchString(raw) {
+		return nil, fmt.Errorf("%q is not a valid instance name (letters, digits, _ and - only)", raw)
+	}
+
+	return raw, nil
+}
+
+func parseHTTPURL(raw string) (any, error) {
+	if raw == "" {
+		return raw, nil
+	}
+
+	parsedURL, err := url.Parse(raw)
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+		return nil, fmt.Errorf("%q is not a valid http(s) URL", raw)
+	}
+
+	return raw, nil
+}
+
+// configKeyRef identifies a setting of the config file.
+type configKeyRef struct {
+	// FileKey is the full key in the config file, e.g. instances.dev.port.
+	FileKey string
+	// Instance is the instance profile the key belongs to, empty for global settings.
+	Instance string
+	// Setting is the definition of the setting.
+	Setting configSetting
+}
+
+// parseConfigKey resolves the key as passed on the command line.
+//
+// Instance settings can be referenced with their full key (instances.<name>.<key>)
+// or with the plain key, in which case they refer to the active instance.
+func parseConfigKey(key string) (configKeyRef, error) {
+	key = strings.ToLower(strings.TrimSpace(key))
+
+	for _, setting := range globalSettings {
+		if setting.Key == key {
+			return configKeyRef{FileKey: key, Setting: setting}, nil
+		}
+	}
+
+	if setting, exists := findInstanceSetting(key); exists {
+		instance := activeInstanceName()
+		return configKeyRef{
+			FileKey:  fmt.Sprintf("instances.%s.%s", instance, key),
+			Instance: instance,
+			Setting:  setting,
+		}, nil
+	}
+
+	parts := strings.Split(key, ".")
+	if len(parts) == 3 && parts[0] == "instances" {
+		_, err := parseInstanceName(parts[1])
+		if err != nil {
+			return configKeyRef{}, err
+		}
+
+		if setting, exists := findInstanceSetting(parts[2]); exists {
+			return configKeyRef{FileKey: key, Instance: parts[1], Setting: setting}, nil
+		}
+	}
+
+	return configKeyRef{}, ErrUnknownConfigKey(key)
+}
+
+// validateConfigSettings checks the settings of the config file against the schema,
+// returning all the problems found.
+func validateConfigSettings(settings map[string]any) []error {
+	var problems []error
+
+	flatSettings := flattenSettings(settings, "")
+	keys := make([]string, 0, len(flatSettings))
+	for key := range flatSettings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := flatSettings[key]
+		if instanceName, found := strings.CutPrefix(key, "instances."); found && !strings.Contains(instanceName, ".") {
+			// empty instance profile, using only the default values
+			_, err := parseInstanceName(instanceName)
+			if err != nil {
+				problems = append(problems, fmt.Errorf("%s: %w", key, err))
+			}
+			if _, isMap := value.(map[string]any); value != nil && !isMap {
+				problems = append(problems, fmt.Errorf("%s: an instance profile must be a map of settings", key))
+			}
+			continue
+		}
+
+		ref, err := parseFileKey(key)
+		if err != nil {
+			problems = append(problems, err)
+			continue
+		}
+
+		_, err = ref.Setting.Parse(cast.ToString(value))
+		if err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+
+	if currentInstance, exists := settings["current_instance"]; exists {
+		name := cast.ToString(currentInstance)
+		instances, _ := settings["instances"].(map[string]any)
+		if _, defined := instances[name]; !defined && name != defaultInstanceName {
+			problems = append(problems, fmt.Errorf("current_instance: instance %q is not defined", name))
+		}
+	}
+
+	return problems
+}
+
+// parseFileKey resolves a full key of the config file,
+// where plain instance settings are the top level fallback values.
+func parseFileKey(key string) (configKeyRef, error) {
+	for _, setting := range globalSettings {
+		if setting.Key == key {
+			return configKeyRef{FileKey: key, Setting: setting}, nil
+		}
+	}
+
+	if setting, exists := findInstanceSetting(key); exists {
+		return configKeyRef{FileKey: key, Setting: setting}, nil
+	}
+
+	return parseConfigKey(key)
+}
+
+// flattenSettings converts the nested settings to a map of dot separated keys.
+func flattenSettings(settings map[string]any, prefix string) map[string]any {
+	flatSettings := make(map[string]any)
+	for key, value := range settings {
+		fullKey := prefix + key
+		if nested, ok := value.(map[string]any); ok && len(nested) > 0 {
+			for nestedKey, nestedValue := range flattenSettings(nested, fullKey+".") {
+				flatSettings[nestedKey] = nestedValue
+			}
+			continue
+		}
+
+		flatSettings[fullKey] = value
+	}
+
+	return flatSettings
+}
@@ -0,0 +1,98 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/saniales/meow-cli/pkg/providers/docker"
+)
+
+var downCmd = &cobra.Command{
+	Use:     "down",
+	Short:   "Stops the cat container on the current machine",
+	Long:    `Stops the cat container on the current machine, optionally removing it`,
+	Example: "meow down --remove",
+	Run:     executeDown,
+}
+
+var downCmdFlags struct {
+	remove bool
+}
+
+func init() {
+	rootCmd.AddCommand(downCmd)
+
+	addCatContainerNameFlag(downCmd.Flags())
+	downCmd.Flags().BoolVar(&downCmdFlags.remove, "remove", false, "Remove the cat container after stopping it (default is false)")
+}
+
+// executeDown performs the "down" logic.
+func executeDown(cmd *cobra.Command, args []string) {
+	instance, err := resolveCatInstance(cmd)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	err = runDown(cmd.Context(), instance)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+func runDown(ctx context.Context, instance catInstance) error {
+	dockerClient, err := docker.NewDockerClient(nil)
+	if err != nil {
+		return err
+	}
+	defer dockerClient.Close()
+
+	exists, running, err := dockerClient.CatContainerExists(ctx, instance.ContainerName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		slog.Info("The cat container does not exist", slog.String("container", instance.ContainerName))
+		return nil
+	}
+
+	if running {
+		slog.Info("Stopping the cat...", slog.String("container", instance.ContainerName))
+		err = dockerClient.StopCatContainer(ctx, instance.ContainerName)
+		if err != nil {
+			return err
+		}
+	}
+
+	if downCmdFlags.remove {
+		slog.Info("Removing the cat container...", slog.String("container", instance.ContainerName))
+		err = dockerClient.RemoveCatContainer(ctx, instance.ContainerName)
+		if err != nil {
+			return err
+		}
+	}
+	slog.Info("The cat is down", slog.String("container", instance.ContainerName))
+
+	return nil
+}
@@ -0,0 +1,73 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownConfigKey is returned when a key is not part of the config schema.
+func ErrUnknownConfigKey(key string) error {
+	return fmt.Errorf("unknown config key %q, use \"meow config list\" to show the valid keys", key)
+}
+
+// ErrInstanceNotDefined is returned when the selected instance profile is not in the config file.
+func ErrInstanceNotDefined(name string) error {
+	return fmt.Errorf("instance %q is not defined in the config file", name)
+}
+
+// ErrInvalidPluginSettings is returned when the plugin settings do not match their schema.
+func ErrInvalidPluginSettings(pluginID string, problems []error) error {
+	return fmt.Errorf("invalid settings for plugin %q:\n%w", pluginID, errors.Join(problems...))
+}
+
+// ErrCatNotRunning is returned when a command requires the cat container to be running.
+func ErrCatNotRunning(containerName string) error {
+	return fmt.Errorf("the cat container %q is not running, start it with \"meow up\"", containerName)
+}
+
+// ErrLintFailed is returned when the plugin linter finds blocking issues.
+func ErrLintFailed(count int) error {
+	return fmt.Errorf("the plugin has %d blocking issues", count)
+}
+
+// ErrChecksumMismatch is returned when a plugin archive does not match the checksum of the lockfile.
+func ErrChecksumMismatch(pluginID string, expected string, actual string) error {
+	return fmt.Errorf("checksum mismatch for plugin %q: the lockfile expects %s, the source archive is %s", pluginID, expected, actual)
+}
+
+// ErrConfirmationRequired is returned when a destructive command runs without a terminal and without --yes.
+func ErrConfirmationRequired(action string) error {
+	return fmt.Errorf("refusing to %s without confirmation in non-interactive mode, pass --yes to confirm", action)
+}
+
+// ErrIngestFailed is returned when some of the documents could not be ingested.
+func ErrIngestFailed(failed int, total int) error {
+	return fmt.Errorf("%d of %d documents could not be ingested", failed, total)
+}
+
+// ErrInvalidFactorySettings is returned when an LLM or embedder configuration does not match its schema.
+func ErrInvalidFactorySettings(factory string, name string, problems []error) error {
+	return fmt.Errorf("invalid %s configuration %q:\n%w", factory, name, errors.Join(problems...))
+}
+
+// ErrSecretInArguments is returned when a secret field of an LLM or embedder configuration is passed on the command line.
+func ErrSecretInArguments(field string, envName string) error {
+	return fmt.Errorf("refusing to read the secret field %q from the arguments, which end up in the shell history: set the %s env variable instead (required secrets are also asked on the terminal)", field, envName)
+}
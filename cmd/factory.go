@@ -0,0 +1,418 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/cobra"
+
+	"github.com/saniales/meow-cli/pkg/providers/cat"
+	"github.com/saniales/meow-cli/pkg/schema"
+)
+
+// catFactory describes a factory of the cat (LLM or embedder), whose provider is selected with a configuration.
+type catFactory struct {
+	// Name is the command name, also used in the secret env variables (CCAT_<NAME>_<FIELD>).
+	Name string
+	// Title is the name shown in the messages.
+	Title   string
+	example string
+
+	getSettings func(client *cat.Client, ctx context.Context) (*cat.FactorySettings, error)
+	getSetting  func(client *cat.Client, ctx context.Context, name string) (*cat.SchemaSetting, error)
+	update      func(client *cat.Client, ctx context.Context, name string, value map[string]any) (*cat.SchemaSetting, error)
+
+	flags struct {
+		file        string
+		schema      bool
+		showSecrets bool
+	}
+}
+
+var llmFactory = &catFactory{
+	Name:        "llm",
+	Title:       "LLM",
+	example:     "LLMOpenAIChatConfig",
+	getSettings: (*cat.Client).GetLLMSettings,
+	getSetting:  (*cat.Client).GetLLMSetting,
+	update:      (*cat.Client).UpdateLLMSetting,
+}
+
+var embedderFactory = &catFactory{
+	Name:        "embedder",
+	Title:       "embedder",
+	example:     "EmbedderOpenAIConfig",
+	getSettings: (*cat.Client).GetEmbedderSettings,
+	getSetting:  (*cat.Client).GetEmbedderSetting,
+	update:      (*cat.Client).UpdateEmbedderSetting,
+}
+
+// secretFieldName matches the names of the fields holding credentials, e.g. openai_api_key or access_token.
+var secretFieldName = regexp.MustCompile(`(?i)(^|_)(key|token|secret|password)$`)
+
+func init() {
+	rootCmd.AddCommand(newFactoryCmd(llmFactory))
+	rootCmd.AddCommand(newFactoryCmd(embedderFactory))
+}
+
+// newFactoryCmd builds the list, get and set commands of the factory.
+func newFactoryCmd(factory *catFactory) *cobra.Command {
+	factoryCmd := &cobra.Command{
+		Use:   factory.Name,
+		Short: fmt.Sprintf("Manages the %s of the cat", factory.Title),
+		Long:  fmt.Sprintf(`Lists the %s providers supported by the cat, shows and selects their configuration`, factory.Title),
+	}
+	addCatAPIFlags(factoryCmd.PersistentFlags())
+
+	listCmd := &cobra.Command{
+		Use:     "list",
+		Short:   fmt.Sprintf("Lists the %s providers", factory.Title),
+		Long:    fmt.Sprintf(`Lists the %s configurations supported by the cat, marking the selected one`, factory.Title),
+		Example: fmt.Sprintf("meow %s list", factory.Name),
+		Args:    cobra.NoArgs,
+		Run:     factory.executeList,
+	}
+
+	getCmd := &cobra.Command{
+		Use:   "get [configuration]",
+		Short: fmt.Sprintf("Shows an %s configuration", factory.Title),
+		Long: fmt.Sprintf(`Shows the values of an %s configuration (the selected one by default), with the secrets masked,
+or its fields with --schema.`, factory.Title),
+		Example: fmt.Sprintf(`meow %[1]s get
+meow %[1]s get %[2]s --schema`, factory.Name, factory.example),
+		Args: cobra.MaximumNArgs(1),
+		Run:  factory.executeGet,
+	}
+	getCmd.Flags().BoolVar(&factory.flags.schema, "schema", false, "Show the fields of the configuration instead of their values (default is false)")
+	getCmd.Flags().BoolVar(&factory.flags.showSecrets, "show-secrets", false, "Show the secret values instead of masking them (default is false)")
+
+	setCmd := &cobra.Command{
+		Use:   "set <configuration> [field=value...]",
+		Short: fmt.Sprintf("Selects and configures an %s provider", factory.Title),
+		Long: fmt.Sprintf(`Selects an %[1]s configuration, setting its fields from the key=value pairs or the --file JSON/YAML file,
+on top of the values the configuration had.
+
+The values are validated against the configuration schema before being sent to the cat.
+To keep the secrets (API keys, tokens, passwords) out of the shell history, they are refused as key=value pairs and read
+from the CCAT_%[2]s_<FIELD> env variables, e.g. CCAT_%[2]s_API_KEY, or asked on the terminal when required and missing.`,
+			factory.Title, strings.ToUpper(factory.Name)),
+		Example: fmt.Sprintf(`meow %[1]s set %[2]s
+meow %[1]s set %[2]s model_name=gpt-4o temperature=0.3
+meow %[1]s set %[2]s --file %[1]s.yaml`, factory.Name, factory.example),
+		Args: cobra.MinimumNArgs(1),
+		Run:  factory.executeSet,
+	}
+	setCmd.Flags().StringVarP(&factory.flags.file, "file", "f", "", "JSON or YAML file with the fields to set (default is none)")
+	setCmd.Flags().BoolVar(&factory.flags.showSecrets, "show-secrets", false, "Show the secret values instead of masking them (default is false)")
+
+	factoryCmd.AddCommand(listCmd, getCmd, setCmd)
+
+	return factoryCmd
+}
+
+// executeList performs the "<factory> list" logic.
+func (factory *catFactory) executeList(cmd *cobra.Command, args []string) {
+	catClient, err := resolveCatClient(cmd)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	settings, err := factory.getSettings(catClient, cmd.Context())
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if globalFlags.json {
+		for index := range settings.Settings {
+			settings.Settings[index].Value = factory.maskSecrets(settings.Settings[index])
+		}
+		err = printJSON(settings)
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	table := newTableWriter(os.Stdout)
+	defer table.Flush()
+
+	fmt.Fprintln(table, "NAME\tSELECTED\tPROVIDER\tDESCRIPTION")
+	for _, setting := range settings.Settings {
+		selected := ""
+		if setting.Name == settings.SelectedConfiguration {
+			selected = "*"
+		}
+		fmt.Fprintf(
+			table,
+			"%s\t%s\t%s\t%s\n",
+			setting.Name,
+			selected,
+			cast.ToString(setting.Schema["humanReadableName"]),
+			truncate(cast.ToString(setting.Schema["description"]), 60),
+		)
+	}
+}
+
+// executeGet performs the "<factory> get" logic.
+func (factory *catFactory) executeGet(cmd *cobra.Command, args []string) {
+	catClient, err := resolveCatClient(cmd)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	err = factory.runGet(cmd.Context(), catClient, args)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+func (factory *catFactory) runGet(ctx context.Context, catClient *cat.Client, args []string) error {
+	var name string
+	if len(args) == 1 {
+		name = args[0]
+	} else {
+		settings, err := factory.getSettings(catClient, ctx)
+		if err != nil {
+			return err
+		}
+		name = settings.SelectedConfiguration
+	}
+
+	setting, err := factory.getSetting(catClient, ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if !factory.flags.schema {
+		return printPluginSettings(factory.maskSecrets(*setting), nil)
+	}
+
+	settingSchema, err := schema.Parse(setting.Schema)
+	if err != nil {
+		return fmt.Errorf("cannot parse the schema of %q: %w", name, err)
+	}
+	if globalFlags.json {
+		return printJSON(setting.Schema)
+	}
+
+	table := newTableWriter(os.Stdout)
+	defer table.Flush()
+
+	fmt.Fprintln(table, "FIELD\tTYPE\tREQUIRED\tSECRET\tDEFAULT\tDESCRIPTION")
+	for _, field := range settingSchema.PropertyNames() {
+		property, _ := settingSchema.Property(field)
+		defaultValue := ""
+		if property.Default != nil {
+			defaultValue = fmt.Sprint(property.Default)
+		}
+		fmt.Fprintf(
+			table,
+			"%s\t%s\t%t\t%t\t%s\t%s\n",
+			field,
+			strings.Join(property.Types(settingSchema), " | "),
+			isRequiredField(settingSchema, field),
+			isSecretField(settingSchema, field),
+			truncate(defaultValue, 30),
+			truncate(property.Description, 60),
+		)
+	}
+
+	return nil
+}
+
+// executeSet performs the "<factory> set" logic.
+func (factory *catFactory) executeSet(cmd *cobra.Command, args []string) {
+	catClient, err := resolveCatClient(cmd)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	err = factory.runSet(cmd.Context(), catClient, args[0], args[1:])
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+func (factory *catFactory) runSet(ctx context.Context, catClient *cat.Client, name string, pairs []string) error {
+	setting, err := factory.getSetting(catClient, ctx, name)
+	if err != nil {
+		return err
+	}
+
+	settingSchema, err := schema.Parse(setting.Schema)
+	if err != nil {
+		return fmt.Errorf("cannot parse the schema of %q: %w", name, err)
+	}
+
+	// the previous values of the configuration are kept, falling back to the schema defaults
+	value := settingSchema.Defaults()
+	for field, fieldValue := range setting.Value {
+		value[field] = fieldValue
+	}
+
+	if factory.flags.file != "" {
+		fileValue, err := readSettingsFile(factory.flags.file)
+		if err != nil {
+			return err
+		}
+		for field, fieldValue := range fileValue {
+			value[field] = fieldValue
+		}
+	}
+
+	for _, field := range settingSchema.PropertyNames() {
+		if !isSecretField(settingSchema, field) {
+			continue
+		}
+		envName := factory.secretEnvName(field)
+		if envValue, exists := os.LookupEnv(envName); exists {
+			slog.Debug("Secret read from the environment", slog.String("field", field), slog.String("env", envName))
+			value[field] = envValue
+		}
+	}
+
+	err = factory.setPairs(settingSchema, value, pairs)
+	if err != nil {
+		return err
+	}
+
+	err = promptMissingSecrets(settingSchema, value)
+	if err != nil {
+		return err
+	}
+
+	problems := settingSchema.Validate(value)
+	if len(problems) > 0 {
+		return ErrInvalidFactorySettings(factory.Title, name, problems)
+	}
+
+	updated, err := factory.update(catClient, ctx, name, value)
+	if err != nil {
+		return err
+	}
+
+	if globalFlags.json {
+		return printJSON(factory.maskSecrets(*updated))
+	}
+	slog.Info(fmt.Sprintf("The cat %s is configured", factory.Title), slog.String("configuration", name))
+	return nil
+}
+
+// setPairs sets the fields of value from the field=value pairs of the command line,
+// refusing the secret fields so that they do not end up in the shell history.
+func (factory *catFactory) setPairs(settingSchema *schema.Schema, value map[string]any, pairs []string) error {
+	for _, pair := range pairs {
+		field, rawValue, found := strings.Cut(pair, "=")
+		if !found || field == "" {
+			return fmt.Errorf("invalid field %q, use the field=value format", pair)
+		}
+		if isSecretField(settingSchema, field) {
+			return ErrSecretInArguments(field, factory.secretEnvName(field))
+		}
+
+		fieldValue, err := settingSchema.ParseValue(field, rawValue)
+		if err != nil {
+			return err
+		}
+		value[field] = fieldValue
+	}
+
+	return nil
+}
+
+// secretEnvName returns the env variable holding the secret field (CCAT_<NAME>_<FIELD>).
+func (factory *catFactory) secretEnvName(field string) string {
+	return fmt.Sprintf("CCAT_%s_%s", strings.ToUpper(factory.Name), strings.ToUpper(field))
+}
+
+// promptMissingSecrets asks on the terminal the required secret fields which are empty.
+func promptMissingSecrets(settingSchema *schema.Schema, value map[string]any) error {
+	if !isInteractive() {
+		return nil
+	}
+
+	for _, field := range settingSchema.PropertyNames() {
+		if !isSecretField(settingSchema, field) || !isRequiredField(settingSchema, field) || cast.ToString(value[field]) != "" {
+			continue
+		}
+
+		secret, err := promptSecret(field)
+		if err != nil {
+			return err
+		}
+		value[field] = secret
+	}
+
+	return nil
+}
+
+// maskSecrets returns the configuration values with the secret ones masked, unless --show-secrets is set.
+func (factory *catFactory) maskSecrets(setting cat.SchemaSetting) map[string]any {
+	if factory.flags.showSecrets {
+		return setting.Value
+	}
+
+	settingSchema, err := schema.Parse(setting.Schema)
+	if err != nil {
+		settingSchema = new(schema.Schema)
+	}
+
+	masked := make(map[string]any, len(setting.Value))
+	for field, fieldValue := range setting.Value {
+		if cast.ToString(fieldValue) != "" && isSecretField(settingSchema, field) {
+			fieldValue = "********"
+		}
+		masked[field] = fieldValue
+	}
+
+	return masked
+}
+
+// isSecretField checks whether the field holds a credential, from its schema format or its name.
+func isSecretField(settingSchema *schema.Schema, field string) bool {
+	property, exists := settingSchema.Property(field)
+	if exists && property.Format == "password" {
+		return true
+	}
+
+	return secretFieldName.MatchString(field)
+}
+
+// isRequiredField checks whether the field is required by the schema.
+func isRequiredField(settingSchema *schema.Schema, field string) bool {
+	for _, required := range settingSchema.Required {
+		if required == field {
+			return true
+		}
+	}
+
+	return false
+}
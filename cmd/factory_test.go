@@ -0,0 +1,77 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/saniales/meow-cli/pkg/schema"
+)
+
+func TestFactorySetPairs(t *testing.T) {
+	settingSchema, err := schema.Parse(map[string]any{
+		"title": "LLMOpenAIChatConfig",
+		"type":  "object",
+		"properties": map[string]any{
+			"openai_api_key": map[string]any{"type": "string"},
+			"passphrase":     map[string]any{"type": "string", "format": "password"},
+			"model_name":     map[string]any{"type": "string"},
+			"temperature":    map[string]any{"type": "number"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		pairs   []string
+		want    map[string]any
+		wantErr string
+	}{
+		{name: "fields", pairs: []string{"model_name=gpt-4o", "temperature=0.3"}, want: map[string]any{"model_name": "gpt-4o", "temperature": 0.3}},
+		{name: "secret from its name", pairs: []string{"openai_api_key=sk-123"}, wantErr: "CCAT_LLM_OPENAI_API_KEY"},
+		{name: "secret from its format", pairs: []string{"model_name=gpt-4o", "passphrase=abc"}, wantErr: "CCAT_LLM_PASSPHRASE"},
+		{name: "missing value", pairs: []string{"model_name"}, wantErr: "field=value"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value := map[string]any{}
+			err := llmFactory.setPairs(settingSchema, value, test.pairs)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Errorf("setPairs() error = %v, want it to contain %q", err, test.wantErr)
+				}
+				if strings.Contains(err.Error(), "sk-123") || strings.Contains(err.Error(), "abc") {
+					t.Errorf("setPairs() error = %v, want the secret value not to be shown", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("setPairs() error = %v", err)
+			}
+			if !reflect.DeepEqual(value, test.want) {
+				t.Errorf("setPairs() value = %v, want %v", value, test.want)
+			}
+		})
+	}
+}
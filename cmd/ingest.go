@@ -0,0 +1,487 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/cheggaaa/pb/v3"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/saniales/meow-cli/pkg/ingest"
+	"github.com/saniales/meow-cli/pkg/providers/cat"
+)
+
+var ingestCmd = &cobra.Command{
+	Use:   "ingest <paths or URLs...>",
+	Short: "Feeds documents to the cat",
+	Long: `Uploads files, folders, globs and web pages to the rabbit hole of the cat,
+which splits them into chunks stored in the declarative memory.
+
+Folders are walked recursively skipping hidden files, and the --include and --exclude
+.gitignore-style globs filter the files found in folders and globs.
+The uploads run concurrently, and a summary of the successes and failures is shown at the end.
+
+The checksums of the ingested files are kept in a manifest of the instance: with --sync,
+only the new and modified files are ingested, and the memories of the ingested files which were deleted are removed.
+The cat ingests the uploads in background, so the memories of the previous version of a modified file
+are deleted only once the first memories of the new version are stored: if none appears within --sync-wait,
+the previous memories are kept and the file is uploaded again by the next sync.
+Since the cat may still fail after storing the first memories, check its logs when replacing important documents.`,
+	Example: `meow ingest handbook.pdf https://cheshirecat.ai/
+meow ingest ./docs --include "*.md" --exclude drafts/
+meow ingest "./docs/**/*.txt" --chunk-size 512 --chunk-overlap 64 --workers 8
+meow ingest ./docs --sync`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  executeIngest,
+}
+
+var ingestCmdFlags struct {
+	include      []string
+	exclude      []string
+	chunkSize    int
+	chunkOverlap int
+	workers      int
+	sync         bool
+	syncWait     time.Duration
+}
+
+func init() {
+	rootCmd.AddCommand(ingestCmd)
+
+	addCatAPIFlags(ingestCmd.Flags())
+	ingestCmd.Flags().StringSliceVar(&ingestCmdFlags.include, "include", nil, "Globs of the files to ingest from folders, can be repeated (default is all the files)")
+	ingestCmd.Flags().StringSliceVar(&ingestCmdFlags.exclude, "exclude", nil, "Globs of the files and folders to skip, can be repeated (default is none)")
+	ingestCmd.Flags().IntVar(&ingestCmdFlags.chunkSize, "chunk-size", 0, "Size of the chunks the documents are split into (default is the cat setting)")
+	ingestCmd.Flags().IntVar(&ingestCmdFlags.chunkOverlap, "chunk-overlap", 0, "Overlap between consecutive chunks (default is the cat setting)")
+	ingestCmd.Flags().IntVarP(&ingestCmdFlags.workers, "workers", "w", 4, "Number of concurrent uploads")
+	ingestCmd.Flags().BoolVar(&ingestCmdFlags.sync, "sync", false, "Ingest only new and modified files, removing the memories of deleted files (default is false)")
+	ingestCmd.Flags().DurationVar(&ingestCmdFlags.syncWait, "sync-wait", 5*time.Minute, "Maximum wait for the cat to store the memories of the modified files before deleting the previous ones")
+}
+
+// executeIngest performs the "ingest" logic.
+func executeIngest(cmd *cobra.Command, args []string) {
+	instance, err := resolveCatInstance(cmd)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	err = runIngest(cmd.Context(), instance, args)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+func runIngest(ctx context.Context, instance catInstance, args []string) error {
+	if ingestCmdFlags.workers < 1 {
+		return fmt.Errorf("the number of workers must be at least 1")
+	}
+	if ingestCmdFlags.syncWait < 0 {
+		return fmt.Errorf("--sync-wait cannot be negative")
+	}
+
+	catClient, err := newCatClient(instance)
+	if err != nil {
+		return err
+	}
+
+	sources, err := ingest.Collect(args, ingest.CollectConfig{
+		Include: ingestCmdFlags.include,
+		Exclude: ingestCmdFlags.exclude,
+	})
+	if err != nil {
+		return err
+	}
+
+	manifestPath, err := ingestManifestPath(instance.Name)
+	if err != nil {
+		return err
+	}
+	manifest, err := ingest.ReadManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+	plan := manifest.Plan(sources)
+
+	var syncReport *ingestSyncReport
+	var previousPoints map[string][]string
+	if ingestCmdFlags.sync {
+		syncReport, previousPoints, err = syncIngestManifest(ctx, catClient, manifest, plan)
+		if err != nil {
+			return err
+		}
+		sources = append(plan.Added, plan.Updated...)
+	}
+
+	var results []ingest.Result
+	switch {
+	case len(sources) > 0:
+		results = ingestSources(ctx, catClient, sources)
+	case ingestCmdFlags.sync:
+		slog.Info("The ingested documents are up to date")
+	default:
+		slog.Warn("No documents to ingest")
+	}
+
+	// the memories of the previous version are deleted only once the cat stored the ones of the new version,
+	// to never lose them if the upload or the background ingestion fails
+	replacedPoints := make(map[string][]string, len(previousPoints))
+	for _, result := range results {
+		if pointIDs := previousPoints[result.Source.Location]; result.Error == nil && len(pointIDs) > 0 {
+			replacedPoints[result.Source.Location] = pointIDs
+		}
+	}
+	stored, waitErr := waitNewMemoryPoints(ctx, catClient, replacedPoints, ingestCmdFlags.syncWait)
+
+	for index, result := range results {
+		if result.Error != nil {
+			continue
+		}
+
+		location := result.Source.Location
+		if _, replaced := replacedPoints[location]; replaced {
+			switch {
+			case !stored[location] && waitErr != nil:
+				results[index].Error = fmt.Errorf("uploaded, but the memories of the new version cannot be checked, the previous ones are kept: %w", waitErr)
+				continue
+			case !stored[location]:
+				results[index].Error = fmt.Errorf("uploaded, but no memories of the new version were stored within %s, the previous ones are kept", ingestCmdFlags.syncWait)
+				continue
+			}
+
+			err = deleteMemoryPoints(ctx, catClient, replacedPoints[location])
+			if err != nil {
+				results[index].Error = fmt.Errorf("ingested, but the memories of the previous version cannot be deleted: %w", err)
+				continue
+			}
+		}
+		manifest.Record(result.Source)
+	}
+	if ingestCmdFlags.sync {
+		results = append(results, plan.Failed...)
+	}
+	err = manifest.Write(manifestPath)
+	if err != nil {
+		return fmt.Errorf("cannot save the ingestion manifest: %w", err)
+	}
+
+	return reportIngestResults(results, syncReport)
+}
+
+// ingestSyncReport represents the changes applied by an ingestion with --sync.
+type ingestSyncReport struct {
+	Added     int `json:"added"`
+	Updated   int `json:"updated"`
+	Removed   int `json:"removed"`
+	Unchanged int `json:"unchanged"`
+}
+
+// ingestManifestPath returns the path of the ingestion manifest of the instance.
+func ingestManifestPath(instanceName string) (string, error) {
+	folder, err := dataDir("ingest", instanceName)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(folder, "manifest.json"), nil
+}
+
+// syncIngestManifest deletes the memories of the removed sources, and returns the ids of the memories
+// of the previous version of the updated sources (by location), to delete once they are ingested again.
+//
+// The memories are found by the location of their source rather than by its name, which is not unique.
+func syncIngestManifest(ctx context.Context, catClient *cat.Client, manifest *ingest.Manifest, plan *ingest.SyncPlan) (*ingestSyncReport, map[string][]string, error) {
+	for _, source := range plan.Removed {
+		err := catClient.DeletePointsByMetadata(ctx, defaultMemoryCollection, map[string]any{ingest.LocationMetadataKey: source.Location})
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot delete the memories of the removed file %q: %w", source.Name, err)
+		}
+		manifest.Forget(source)
+		slog.Info("Memories of removed file deleted", slog.String("source", source.Name))
+	}
+
+	previousPoints := make(map[string][]string, len(plan.Updated))
+	if len(plan.Updated) > 0 {
+		updated := make(map[string]bool, len(plan.Updated))
+		for _, source := range plan.Updated {
+			updated[source.Location] = true
+		}
+
+		err := forEachMemoryPoint(ctx, catClient, defaultMemoryCollection, func(point cat.MemoryPoint) {
+			location, _ := point.Payload.Metadata[ingest.LocationMetadataKey].(string)
+			if updated[location] {
+				previousPoints[location] = append(previousPoints[location], point.ID)
+			}
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot list the memories of the modified files: %w", err)
+		}
+	}
+
+	return &ingestSyncReport{
+		Added:     len(plan.Added),
+		Updated:   len(plan.Updated),
+		Removed:   len(plan.Removed),
+		Unchanged: len(plan.Unchanged),
+	}, previousPoints, nil
+}
+
+// waitNewMemoryPoints waits until the declarative memory holds, for each location, a point which is not among its previous ones,
+// returning the locations whose new points were found before the timeout.
+func waitNewMemoryPoints(ctx context.Context, catClient *cat.Client, previousPoints map[string][]string, timeout time.Duration) (map[string]bool, error) {
+	stored := make(map[string]bool, len(previousPoints))
+	if len(previousPoints) == 0 {
+		return stored, nil
+	}
+
+	previous := make(map[string]bool)
+	for _, pointIDs := range previousPoints {
+		for _, pointID := range pointIDs {
+			previous[pointID] = true
+		}
+	}
+
+	slog.Info("Waiting for the cat to store the memories of the modified files...", slog.Int("files", len(previousPoints)))
+	deadline := time.Now().Add(timeout)
+	backoff := 100 * time.Millisecond
+	for {
+		err := forEachMemoryPoint(ctx, catClient, defaultMemoryCollection, func(point cat.MemoryPoint) {
+			location, _ := point.Payload.Metadata[ingest.LocationMetadataKey].(string)
+			if _, pending := previousPoints[location]; pending && !previous[point.ID] {
+				stored[location] = true
+			}
+		})
+		if err != nil {
+			return stored, err
+		}
+		if len(stored) == len(previousPoints) || time.Now().Add(backoff).After(deadline) {
+			return stored, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return stored, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, 2*time.Second)
+	}
+}
+
+// forEachMemoryPoint calls the function with all the points of the collection, fetching all the pages.
+func forEachMemoryPoint(ctx context.Context, catClient *cat.Client, collection string, function func(point cat.MemoryPoint)) error {
+	var offset cat.Offset
+	for {
+		page, err := catClient.GetPoints(ctx, collection, 1000, offset)
+		if err != nil {
+			return err
+		}
+
+		for _, point := range page.Points {
+			function(point)
+		}
+
+		if page.NextOffset == "" {
+			return nil
+		}
+		offset = page.NextOffset
+	}
+}
+
+// deleteMemoryPoints deletes the points of the declarative memory with the ids.
+func deleteMemoryPoints(ctx context.Context, catClient *cat.Client, pointIDs []string) error {
+	for _, pointID := range pointIDs {
+		err := catClient.DeletePoint(ctx, defaultMemoryCollection, pointID)
+		if err != nil && !errors.Is(err, cat.ErrNotFound) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ingestSources uploads the sources showing their progress, as progress bars on a terminal or as logs otherwise.
+func ingestSources(ctx context.Context, catClient *cat.Client, sources []ingest.Source) []ingest.Result {
+	workers := min(ingestCmdFlags.workers, len(sources))
+	config := ingest.Config{
+		Workers: workers,
+		Options: cat.UploadOptions{
+			ChunkSize:    ingestCmdFlags.chunkSize,
+			ChunkOverlap: ingestCmdFlags.chunkOverlap,
+		},
+	}
+	slog.Info("Ingesting documents...", slog.Int("documents", len(sources)), slog.Int("workers", workers))
+
+	if !globalFlags.json && !globalFlags.quiet && term.IsTerminal(int(os.Stdout.Fd())) {
+		progress, err := newIngestProgress(workers, len(sources))
+		if err == nil {
+			config.OnStart = progress.start
+			config.OnDone = progress.done
+			results := ingest.Ingest(ctx, catClient, sources, config)
+			progress.stop()
+			return results
+		}
+		slog.Debug("Cannot show the progress bars", slog.String("error", err.Error()))
+	}
+
+	config.OnDone = func(worker int, result ingest.Result) {
+		if result.Error != nil {
+			slog.Error("Ingestion failed", slog.String("source", result.Source.Name), slog.String("error", result.Error.Error()))
+			return
+		}
+		slog.Info("Document ingested", slog.String("source", result.Source.Name), slog.Duration("elapsed", result.Elapsed.Truncate(time.Millisecond)))
+	}
+
+	return ingest.Ingest(ctx, catClient, sources, config)
+}
+
+// ingestProgress shows a progress bar for each worker, with the file being uploaded, and one for the whole ingestion.
+type ingestProgress struct {
+	pool    *pb.Pool
+	workers []*pb.ProgressBar
+	total   *pb.ProgressBar
+	mutex   sync.Mutex
+}
+
+func newIngestProgress(workers int, sources int) (*ingestProgress, error) {
+	progress := &ingestProgress{
+		total: pb.New(sources).SetTemplateString(`{{string . "prefix"}} {{counters . }} {{bar . }} {{percent . }} {{etime . }}`),
+	}
+	progress.total.Set("prefix", "documents")
+
+	bars := []*pb.ProgressBar{progress.total}
+	for worker := 0; worker < workers; worker++ {
+		bar := pb.New(0).SetTemplateString(`{{string . "prefix"}} {{counters . }} {{bar . }} {{percent . }}{{with string . "suffix"}} {{.}}{{end}}`)
+		bar.Set(pb.Bytes, true)
+		bar.Set("prefix", fmt.Sprintf("%-30s", "waiting"))
+		progress.workers = append(progress.workers, bar)
+		bars = append(bars, bar)
+	}
+
+	pool, err := pb.StartPool(bars...)
+	if err != nil {
+		return nil, err
+	}
+	progress.pool = pool
+
+	return progress, nil
+}
+
+func (progress *ingestProgress) start(worker int, source ingest.Source, reader io.Reader) io.Reader {
+	progress.mutex.Lock()
+	defer progress.mutex.Unlock()
+
+	bar := progress.workers[worker]
+	bar.SetTotal(source.Size)
+	bar.SetCurrent(0)
+	bar.Set("prefix", fmt.Sprintf("%-30s", truncate(source.Name, 30)))
+	bar.Set("suffix", "")
+	if reader == nil {
+		bar.Set("suffix", "fetching web page")
+		return nil
+	}
+
+	return bar.NewProxyReader(reader)
+}
+
+func (progress *ingestProgress) done(worker int, result ingest.Result) {
+	progress.mutex.Lock()
+	defer progress.mutex.Unlock()
+
+	bar := progress.workers[worker]
+	if result.Error != nil {
+		bar.Set("suffix", "failed")
+	} else {
+		bar.SetCurrent(bar.Total())
+		bar.Set("suffix", "done")
+	}
+	progress.total.Increment()
+}
+
+func (progress *ingestProgress) stop() {
+	progress.total.Finish()
+	for _, bar := range progress.workers {
+		bar.Finish()
+	}
+	progress.pool.Stop()
+}
+
+// reportIngestResults shows the summary of the ingestion, failing if some documents were not ingested.
+func reportIngestResults(results []ingest.Result, syncReport *ingestSyncReport) error {
+	type failure struct {
+		Source string `json:"source"`
+		Error  string `json:"error"`
+	}
+	report := struct {
+		Ingested []string          `json:"ingested"`
+		Failed   []failure         `json:"failed"`
+		Sync     *ingestSyncReport `json:"sync,omitempty"`
+	}{
+		Ingested: []string{},
+		Failed:   []failure{},
+		Sync:     syncReport,
+	}
+	for _, result := range results {
+		if result.Error != nil {
+			report.Failed = append(report.Failed, failure{Source: result.Source.Name, Error: result.Error.Error()})
+			continue
+		}
+		report.Ingested = append(report.Ingested, result.Source.Name)
+	}
+
+	if globalFlags.json {
+		err := printJSON(report)
+		if err != nil {
+			return err
+		}
+	} else if len(report.Failed) > 0 {
+		table := newTableWriter(os.Stdout)
+		fmt.Fprintln(table, "FAILED\tERROR")
+		for _, failure := range report.Failed {
+			fmt.Fprintf(table, "%s\t%s\n", failure.Source, truncate(failure.Error, 100))
+		}
+		table.Flush()
+	}
+
+	if syncReport != nil {
+		slog.Info(
+			"Sync completed",
+			slog.Int("added", syncReport.Added),
+			slog.Int("updated", syncReport.Updated),
+			slog.Int("removed", syncReport.Removed),
+			slog.Int("unchanged", syncReport.Unchanged),
+			slog.Int("failed", len(report.Failed)),
+		)
+	} else {
+		slog.Info("Ingestion completed", slog.Int("ingested", len(report.Ingested)), slog.Int("failed", len(report.Failed)))
+	}
+	if len(report.Failed) > 0 {
+		return ErrIngestFailed(len(report.Failed), len(results))
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/saniales/meow-cli/internal/testutil"
+	"github.com/saniales/meow-cli/pkg/ingest"
+	"github.com/saniales/meow-cli/pkg/providers/cat"
+)
+
+// fakeRabbitHole is a cat storing the uploaded files as two points of the declarative memory,
+// in background as the real one, and never storing the files containing FAIL.
+type fakeRabbitHole struct {
+	mutex   sync.Mutex
+	points  []cat.MemoryPoint
+	nextID  int
+	pending sync.WaitGroup
+}
+
+func (rabbitHole *fakeRabbitHole) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	rabbitHole.mutex.Lock()
+	defer rabbitHole.mutex.Unlock()
+
+	const pointsPath = "/memory/collections/declarative/points"
+	switch {
+	case request.Method == http.MethodPost && request.URL.Path == "/rabbithole/":
+		file, header, err := request.FormFile("file")
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		content, _ := io.ReadAll(file)
+		metadata := map[string]any{}
+		_ = json.Unmarshal([]byte(request.FormValue("metadata")), &metadata)
+		metadata["source"] = header.Filename
+
+		if !strings.Contains(string(content), "FAIL") {
+			rabbitHole.pending.Add(1)
+			go func() {
+				defer rabbitHole.pending.Done()
+				time.Sleep(20 * time.Millisecond)
+				rabbitHole.mutex.Lock()
+				defer rabbitHole.mutex.Unlock()
+				for chunk := 0; chunk < 2; chunk++ {
+					rabbitHole.nextID++
+					rabbitHole.points = append(rabbitHole.points, cat.MemoryPoint{
+						ID:      strconv.Itoa(rabbitHole.nextID),
+						Payload: cat.MemoryPayload{PageContent: string(content), Metadata: metadata},
+					})
+				}
+			}()
+		}
+		_ = json.NewEncoder(writer).Encode(cat.UploadResult{Filename: header.Filename, Info: "File is being ingested asynchronously"})
+	case request.Method == http.MethodGet && request.URL.Path == pointsPath:
+		_ = json.NewEncoder(writer).Encode(cat.MemoryPointsPage{Points: rabbitHole.points})
+	case request.Method == http.MethodDelete && request.URL.Path == pointsPath:
+		var metadata map[string]any
+		_ = json.NewDecoder(request.Body).Decode(&metadata)
+		rabbitHole.deletePoints(func(point cat.MemoryPoint) bool {
+			for key, value := range metadata {
+				if point.Payload.Metadata[key] != value {
+					return false
+				}
+			}
+			return true
+		})
+		_, _ = writer.Write([]byte("{}"))
+	case request.Method == http.MethodDelete && strings.HasPrefix(request.URL.Path, pointsPath+"/"):
+		pointID := strings.TrimPrefix(request.URL.Path, pointsPath+"/")
+		rabbitHole.deletePoints(func(point cat.MemoryPoint) bool {
+			return point.ID == pointID
+		})
+		_, _ = writer.Write([]byte("{}"))
+	default:
+		http.NotFound(writer, request)
+	}
+}
+
+func (rabbitHole *fakeRabbitHole) deletePoints(matches func(point cat.MemoryPoint) bool) {
+	points := rabbitHole.points[:0]
+	for _, point := range rabbitHole.points {
+		if !matches(point) {
+			points = append(points, point)
+		}
+	}
+	rabbitHole.points = points
+}
+
+// contents returns the contents of the points by the location of their source.
+func (rabbitHole *fakeRabbitHole) contents() map[string][]string {
+	rabbitHole.pending.Wait()
+	rabbitHole.mutex.Lock()
+	defer rabbitHole.mutex.Unlock()
+
+	contents := map[string][]string{}
+	for _, point := range rabbitHole.points {
+		location, _ := point.Payload.Metadata[ingest.LocationMetadataKey].(string)
+		contents[location] = append(contents[location], point.Payload.PageContent)
+	}
+	return contents
+}
+
+func TestRunIngestSyncSameNames(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+	t.Setenv("HOME", configDir)
+	flags := ingestCmdFlags
+	defer func() {
+		ingestCmdFlags = flags
+	}()
+	ingestCmdFlags.workers = 2
+	ingestCmdFlags.sync = true
+	ingestCmdFlags.syncWait = 300 * time.Millisecond
+
+	rabbitHole := &fakeRabbitHole{}
+	server := httptest.NewServer(rabbitHole)
+	defer server.Close()
+	instance := catInstance{Name: "default", APIURL: server.URL}
+
+	// both files are named readme.md in the memories
+	root := t.TempDir()
+	first := filepath.Join(root, "a", "readme.md")
+	second := filepath.Join(root, "b", "readme.md")
+	testutil.WriteFiles(t, root, map[string]string{"a/readme.md": "a v1", "b/readme.md": "b v1"})
+
+	steps := []struct {
+		name    string
+		change  func()
+		args    []string
+		want    map[string][]string
+		wantErr bool
+	}{
+		{
+			name: "added",
+			args: []string{first, second},
+			want: map[string][]string{first: {"a v1", "a v1"}, second: {"b v1", "b v1"}},
+		},
+		{
+			name:   "updated",
+			change: func() { testutil.WriteFiles(t, root, map[string]string{"a/readme.md": "a v2"}) },
+			args:   []string{first, second},
+			want:   map[string][]string{first: {"a v2", "a v2"}, second: {"b v1", "b v1"}},
+		},
+		{
+			name:    "updated, but never stored by the cat",
+			change:  func() { testutil.WriteFiles(t, root, map[string]string{"b/readme.md": "b v2 FAIL"}) },
+			args:    []string{first, second},
+			want:    map[string][]string{first: {"a v2", "a v2"}, second: {"b v1", "b v1"}},
+			wantErr: true,
+		},
+		{
+			name: "removed",
+			change: func() {
+				testutil.WriteFiles(t, root, map[string]string{"b/readme.md": "b v1"})
+				err := os.Remove(first)
+				if err != nil {
+					t.Fatal(err)
+				}
+			},
+			args: []string{second},
+			want: map[string][]string{second: {"b v1", "b v1"}},
+		},
+	}
+
+	for _, step := range steps {
+		if step.change != nil {
+			step.change()
+		}
+
+		err := runIngest(context.Background(), instance, step.args)
+		if (err != nil) != step.wantErr {
+			t.Fatalf("%s: runIngest() error = %v, wantErr %v", step.name, err, step.wantErr)
+		}
+		if got := rabbitHole.contents(); !reflect.DeepEqual(got, step.want) {
+			t.Errorf("%s: memories = %v, want %v", step.name, got, step.want)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// defaultInstanceName is the name of the instance used when none is selected.
+const defaultInstanceName = "default"
+
+// configSetting describes a setting of the config file.
+type configSetting struct {
+	// Key is the config key, relative to the instance profile (instances.<name>.<key>) for instance settings.
+	Key string
+	// Flag is the name of the command line flag overriding the setting, if any.
+	Flag string
+	// Default is the value used when the setting is not configured.
+	Default any
+	// Description is shown when listing the config keys.
+	Description string
+	// Parse validates the raw value, returning it with the proper type.
+	Parse configValueParser
+	// Secret settings are masked when printed.
+	Secret bool
+}
+
+// instanceSettings lists all the settings of a cat instance profile.
+var instanceSettings = []configSetting{
+	{Key: "image", Flag: "image", Default: "ghcr.io/cheshire-cat-ai/core", Description: "The cat docker image", Parse: parseNonEmptyString},
+	{Key: "image_version", Flag: "image-version", Default: "latest", Description: "The cat docker image version", Parse: parseNonEmptyString},
+	{Key: "port", Flag: "port", Default: 1865, Description: "The host port the cat listens on", Parse: parsePort},
+	{Key: "container_name", Flag: "name", Default: "cheshire-cat-ai", Description: "The name of the cat container", Parse: parseContainerName},
+	{Key: "plugins_folder", Flag: "plugins-folder", Default: "./plugins", Description: "The host folder mounted as the cat plugins folder", Parse: parseNonEmptyString},
+	{Key: "data_folder", Flag: "data-folder", Default: "./data", Description: "The host folder mounted as the cat data folder", Parse: parseNonEmptyString},
+	{Key: "static_folder", Flag: "static-folder", Default: "./static", Description: "The host folder mounted as the cat static folder", Parse: parseNonEmptyString},
+	{Key: "api_url", Flag: "api-url", Default: "", Description: "The cat API URL (default is http://localhost:<port>)", Parse: parseHTTPURL},
+	{Key: "api_key", Flag: "api-key", Default: "", Description: "The cat API key", Parse: parseString, Secret: true},
+	{Key: "user_id", Flag: "user-id", Default: "", Description: "The user id sent to the cat API", Parse: parseString},
+}
+
+// findInstanceSetting returns the instance setting with the specified key.
+func findInstanceSetting(key string) (configSetting, bool) {
+	for _, setting := range instanceSettings {
+		if setting.Key == key {
+			return setting, true
+		}
+	}
+
+	return configSetting{}, false
+}
+
+// catInstance represents the effective configuration of a cat instance managed
+// by the CLI, resolved from flags, environment and the active config profile.
+type catInstance struct {
+	Name          string
+	Image         string
+	ImageVersion  string
+	Port          int
+	ContainerName string
+	PluginsFolder string
+	DataFolder    string
+	StaticFolder  string
+	APIURL        string
+	APIKey        string
+	UserID        string
+}
+
+// addCatInstanceFlags registers on the flag set all the cat container flags.
+func addCatInstanceFlags(flags *pflag.FlagSet) {
+	flags.String("image", "", "The cat docker image (default is ghcr.io/cheshire-cat-ai/core)")
+	flags.String("image-version", "", "The cat docker image version (default is latest)")
+	flags.Int("port", 0, "The host port the cat will listen on (default is 1865)")
+	flags.String("plugins-folder", "", "The host folder mounted as the cat plugins folder (default is ./plugins)")
+	flags.String("data-folder", "", "The host folder mounted as the cat data folder (default is ./data)")
+	flags.String("static-folder", "", "The host folder mounted as the cat static folder (default is ./static)")
+	addCatContainerNameFlag(flags)
+}
+
+// addCatAPIFlags registers on the flag set the cat API connection flags.
+func addCatAPIFlags(flags *pflag.FlagSet) {
+	flags.String("api-url", "", "The cat API URL (default is http://localhost:<port>)")
+	flags.String("api-key", "", "The cat API key (prefer the CCAT_API_KEY env variable or the config file)")
+	flags.String("user-id", "", "The user id sent to the cat API")
+}
+
+// addCatContainerNameFlag registers on the flag set the cat container name flag.
+func addCatContainerNameFlag(flags *pflag.FlagSet) {
+	flags.String("name", "", "The name of the cat container (default is cheshire-cat-ai, or cheshire-cat-ai-<instance> for named instances)")
+}
+
+// activeInstanceName returns the name of the selected instance profile,
+// from the --instance flag, the CCAT_INSTANCE env variable or the config file.
+func activeInstanceName() string {
+	name, _ := resolveActiveInstance()
+	return name
+}
+
+// resolveActiveInstance returns the name of the selected instance profile and where it comes from.
+func resolveActiveInstance() (name string, source string) {
+	if globalFlags.instance != "" {
+		return globalFlags.instance, "flag"
+	}
+
+	if name, exists := os.LookupEnv("CCAT_INSTANCE"); exists && name != "" {
+		return name, "env"
+	}
+
+	if viper.InConfig("current_instance") {
+		if name := viper.GetString("current_instance"); name != "" {
+			return name, "file"
+		}
+	}
+
+	return defaultInstanceName, "default"
+}
+
+// configuredInstanceNames returns the sorted names of the instance profiles in the config file.
+func configuredInstanceNames() []string {
+	names := make([]string, 0)
+	for name := range viper.GetStringMap("instances") {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// instanceExists checks whether the instance profile is usable.
+//
+// The default instance always exists, since it falls back to the top level settings.
+func instanceExists(name string) bool {
+	return name == defaultInstanceName || viper.InConfig("instances."+name)
+}
+
+// resolveInstanceSetting returns the effective value of an instance setting and where it comes from.
+//
+// The precedence is: command line flag, CCAT_<KEY> env variable, instance profile,
+// top level key of the config file and finally the default value.
+func resolveInstanceSetting(cmd *cobra.Command, instanceName string, setting configSetting) (value any, source string) {
+	if cmd != nil && setting.Flag != "" {
+		flag := cmd.Flags().Lookup(setting.Flag)
+		if flag != nil && flag.Changed {
+			return flag.Value.String(), "flag"
+		}
+	}
+
+	if envValue, exists := os.LookupEnv("CCAT_" + strings.ToUpper(setting.Key)); exists {
+		return envValue, "env"
+	}
+
+	profileKey := fmt.Sprintf("instances.%s.%s", instanceName, setting.Key)
+	if viper.InConfig(profileKey) {
+		return viper.Get(profileKey), "file"
+	}
+
+	if viper.InConfig(setting.Key) {
+		return viper.Get(setting.Key), "file"
+	}
+
+	if setting.Key == "container_name" && instanceName != defaultInstanceName {
+		return fmt.Sprintf("%s-%s", setting.Default, instanceName), "default"
+	}
+
+	return setting.Default, "default"
+}
+
+// resolveCatInstance returns the active cat instance configuration for the command,
+// giving precedence to the flags explicitly set on the command line.
+func resolveCatInstance(cmd *cobra.Command) (catInstance, error) {
+	return resolveCatInstanceWithFallback(cmd, nil)
+}
+
+// resolveCatInstanceWithFallback is like resolveCatInstance, but uses the fallback values by config key
+// instead of the defaults of the settings which are not configured, e.g. the settings saved in a backup.
+func resolveCatInstanceWithFallback(cmd *cobra.Command, fallback map[string]any) (catInstance, error) {
+	name := activeInstanceName()
+	if !instanceExists(name) {
+		return catInstance{}, ErrInstanceNotDefined(name)
+	}
+
+	values := make(map[string]any, len(instanceSettings))
+	for _, setting := range instanceSettings {
+		value, source := resolveInstanceSetting(cmd, name, setting)
+		if fallbackValue, exists := fallback[setting.Key]; exists && source == "default" {
+			value = fallbackValue
+		}
+		values[setting.Key] = value
+	}
+
+	port, err := cast.ToIntE(values["port"])
+	if err != nil || port <= 0 || port > 65535 {
+		return catInstance{}, fmt.Errorf("invalid cat port %v", values["port"])
+	}
+
+	instance := catInstance{
+		Name:          name,
+		Image:         cast.ToString(values["image"]),
+		ImageVersion:  cast.ToString(values["image_version"]),
+		Port:          port,
+		ContainerName: cast.ToString(values["container_name"]),
+		PluginsFolder: cast.ToString(values["plugins_folder"]),
+		DataFolder:    cast.ToString(values["data_folder"]),
+		StaticFolder:  cast.ToString(values["static_folder"]),
+		APIURL:        cast.ToString(values["api_url"]),
+		APIKey:        cast.ToString(values["api_key"]),
+		UserID:        cast.ToString(values["user_id"]),
+	}
+
+	return instance, nil
+}
+
+// FullImage returns the cat image in the "image:version" format.
+func (instance catInstance) FullImage() string {
+	return fmt.Sprintf("%s:%s", instance.Image, instance.ImageVersion)
+}
+
+// BaseURL returns the URL of the cat API, defaulting to the port exposed on the host.
+func (instance catInstance) BaseURL() string {
+	if instance.APIURL != "" {
+		return strings.TrimSuffix(instance.APIURL, "/") + "/"
+	}
+
+	return fmt.Sprintf("http://localhost:%d/", instance.Port)
+}
+
+// prepareFolders makes the instance folders absolute and creates them if missing,
+// since docker bind mounts require absolute paths on the host.
+func (instance *catInstance) prepareFolders() error {
+	for _, folder := range []*string{&instance.PluginsFolder, &instance.DataFolder, &instance.StaticFolder} {
+		absFolder, err := filepath.Abs(*folder)
+		if err != nil {
+			return err
+		}
+
+		err = os.MkdirAll(absFolder, 0o755)
+		if err != nil {
+			return err
+		}
+
+		*folder = absFolder
+	}
+
+	return nil
+}
+
+// absFolders makes the instance folders absolute, without creating them.
+func (instance *catInstance) absFolders() error {
+	for _, folder := range []*string{&instance.PluginsFolder, &instance.DataFolder, &instance.StaticFolder} {
+		absFolder, err := filepath.Abs(*folder)
+		if err != nil {
+			return err
+		}
+
+		*folder = absFolder
+	}
+
+	return nil
+}
+
+// publicSettings returns the effective instance settings by config key, secrets excluded.
+func (instance catInstance) publicSettings() map[string]any {
+	values := map[string]any{
+		"image":          instance.Image,
+		"image_version":  instance.ImageVersion,
+		"port":           instance.Port,
+		"container_name": instance.ContainerName,
+		"plugins_folder": instance.PluginsFolder,
+		"data_folder":    instance.DataFolder,
+		"static_folder":  instance.StaticFolder,
+		"api_url":        instance.APIURL,
+		"api_key":        instance.APIKey,
+		"user_id":        instance.UserID,
+	}
+	for _, setting := range instanceSettings {
+		if setting.Secret {
+			delete(values, setting.Key)
+		}
+	}
+
+	return values
+}
+
+// splitImageReference splits a docker image reference in image and version, defaulting the version to latest.
+func splitImageReference(reference string) (image string, version string) {
+	slash := strings.LastIndex(reference, "/")
+	colon := strings.LastIndex(reference, ":")
+	if colon > slash {
+		return reference[:colon], reference[colon+1:]
+	}
+
+	return reference, "latest"
+}
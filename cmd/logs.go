@@ -0,0 +1,155 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/saniales/meow-cli/pkg/providers/docker"
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Shows the logs of the cat container",
+	Long: `Shows the logs of the cat container.
+
+With --parse (implied by the global --json flag) the cat log lines are parsed
+into structured records, one JSON object per log line in JSON mode.`,
+	Example: "meow logs --follow --since 10m --tail 100",
+	Run:     executeLogs,
+}
+
+var logsCmdFlags struct {
+	follow     bool
+	since      string
+	tail       string
+	timestamps bool
+	parse      bool
+}
+
+func init() {
+	rootCmd.AddCommand(logsCmd)
+
+	addCatContainerNameFlag(logsCmd.Flags())
+	logsCmd.Flags().BoolVarP(&logsCmdFlags.follow, "follow", "f", false, "Follow the log output (default is false)")
+	logsCmd.Flags().StringVar(&logsCmdFlags.since, "since", "", "Show logs since a timestamp (e.g. 2024-06-10T10:00:00Z) or relative duration (e.g. 10m)")
+	logsCmd.Flags().StringVarP(&logsCmdFlags.tail, "tail", "n", "all", "Number of lines to show from the end of the logs")
+	logsCmd.Flags().BoolVarP(&logsCmdFlags.timestamps, "timestamps", "t", false, "Show the docker timestamps (default is false)")
+	logsCmd.Flags().BoolVar(&logsCmdFlags.parse, "parse", false, "Parse the cat log lines into structured records (default is false)")
+}
+
+// executeLogs performs the "logs" logic.
+func executeLogs(cmd *cobra.Command, args []string) {
+	instance, err := resolveCatInstance(cmd)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	err = runLogs(cmd.Context(), instance)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+func runLogs(ctx context.Context, instance catInstance) error {
+	dockerClient, err := docker.NewDockerClient(nil)
+	if err != nil {
+		return err
+	}
+	defer dockerClient.Close()
+
+	var stdout, stderr io.Writer = os.Stdout, os.Stderr
+	if logsCmdFlags.parse || globalFlags.json {
+		catLogger := newCatLogger(os.Stdout)
+		recordWriter := &lineWriter{onLine: func(line string) {
+			logCatRecord(ctx, catLogger, docker.ParseCatLogLine(line))
+		}}
+		defer recordWriter.Flush()
+
+		stdout, stderr = recordWriter, recordWriter
+	}
+
+	return dockerClient.StreamCatLogs(ctx, instance.ContainerName, docker.StreamCatLogsConfig{
+		Follow:     logsCmdFlags.follow,
+		Since:      logsCmdFlags.since,
+		Tail:       logsCmdFlags.tail,
+		Timestamps: logsCmdFlags.timestamps,
+	}, stdout, stderr)
+}
+
+// newCatLogger returns the logger of the parsed cat log lines, in the format of the default logger.
+//
+// Unlike the default logger it writes the records of every level, whatever the verbosity of the CLI,
+// so that each cat log line produces exactly one record.
+func newCatLogger(writer io.Writer) *slog.Logger {
+	options := &slog.HandlerOptions{Level: slog.Level(math.MinInt)}
+	if globalFlags.json {
+		return slog.New(slog.NewJSONHandler(writer, options))
+	}
+
+	return slog.New(slog.NewTextHandler(writer, options))
+}
+
+// logCatRecord logs the parsed cat log line through the cat logger.
+func logCatRecord(ctx context.Context, catLogger *slog.Logger, record docker.CatLogRecord) {
+	attrs := []slog.Attr{slog.String("origin", "cat")}
+	if !record.Time.IsZero() {
+		attrs = append(attrs, slog.Time("cat_time", record.Time))
+	}
+	if record.Source != "" {
+		attrs = append(attrs, slog.String("source", record.Source))
+	}
+
+	catLogger.LogAttrs(ctx, record.Level, record.Message, attrs...)
+}
+
+// lineWriter is a writer calling onLine for every complete line written.
+type lineWriter struct {
+	buffer bytes.Buffer
+	onLine func(line string)
+}
+
+func (writer *lineWriter) Write(data []byte) (int, error) {
+	writer.buffer.Write(data)
+	for {
+		index := bytes.IndexByte(writer.buffer.Bytes(), '\n')
+		if index < 0 {
+			return len(data), nil
+		}
+
+		line := writer.buffer.Next(index + 1)
+		writer.onLine(string(bytes.TrimRight(line, "\r\n")))
+	}
+}
+
+// Flush calls onLine for the remaining incomplete line, if any.
+func (writer *lineWriter) Flush() {
+	if writer.buffer.Len() > 0 {
+		writer.onLine(writer.buffer.String())
+		writer.buffer.Reset()
+	}
+}
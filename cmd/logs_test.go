@@ -0,0 +1,76 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/saniales/meow-cli/pkg/providers/docker"
+)
+
+func TestLogCatRecordJSON(t *testing.T) {
+	// the default logger of a --quiet run drops everything below the errors
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelError})))
+	globalFlags.json = true
+	defer func() {
+		slog.SetDefault(defaultLogger)
+		globalFlags.json = false
+	}()
+
+	lines := []string{
+		"[2024-06-10 10:00:00.123] DEBUG  cat.looking_glass.stray_cat..__init__::45 => debug message",
+		"[2024-06-10 10:00:00.456] INFO   cat.mad_hatter.mad_hatter..find_plugins::69 => info message",
+		"WARNING:  Invalid HTTP request received.",
+		"[2024-06-10 10:00:01.000] ERROR  cat.routes.websocket..websocket_endpoint::80 => error message",
+		"a line in no known format",
+	}
+
+	var buffer bytes.Buffer
+	catLogger := newCatLogger(&buffer)
+	recordWriter := &lineWriter{onLine: func(line string) {
+		logCatRecord(context.Background(), catLogger, docker.ParseCatLogLine(line))
+	}}
+	_, err := recordWriter.Write([]byte(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recordWriter.Flush()
+
+	wantLevels := []string{"DEBUG", "INFO", "WARN", "ERROR", "INFO"}
+	decoder := json.NewDecoder(&buffer)
+	for index, wantLevel := range wantLevels {
+		var record map[string]any
+		err := decoder.Decode(&record)
+		if err != nil {
+			t.Fatalf("record %d error = %v, want one JSON object per log line", index, err)
+		}
+		if record["level"] != wantLevel || record["origin"] != "cat" {
+			t.Errorf("record %d = %v, want level %s from the cat", index, record, wantLevel)
+		}
+	}
+	if decoder.More() {
+		t.Errorf("logCatRecord() wrote more records than the %d log lines", len(lines))
+	}
+}
@@ -0,0 +1,385 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/cobra"
+
+	"github.com/saniales/meow-cli/pkg/providers/cat"
+)
+
+// defaultMemoryCollection is the collection of the documents ingested in the cat.
+const defaultMemoryCollection = "declarative"
+
+var memoryCmd = &cobra.Command{
+	Use:   "memory",
+	Short: "Inspects the memory of the cat",
+	Long:  `Inspects the vector memory collections of the cat`,
+}
+
+var memoryCollectionsCmd = &cobra.Command{
+	Use:     "collections",
+	Short:   "Lists the memory collections",
+	Long:    `Lists the vector memory collections of the cat with their number of points`,
+	Example: "meow memory collections",
+	Args:    cobra.NoArgs,
+	Run:     executeMemoryCollections,
+}
+
+var memoryRecallCmd = &cobra.Command{
+	Use:   "recall <text>",
+	Short: "Recalls the memories most similar to a text",
+	Long: `Recalls the memories most similar to a text, as the cat does when answering,
+showing their similarity score, source, timestamp and content.`,
+	Example: `meow memory recall "how do I install a plugin?" --k 5
+meow memory recall "pizza" --collection episodic --json`,
+	Args: cobra.ExactArgs(1),
+	Run:  executeMemoryRecall,
+}
+
+var memoryPointsCmd = &cobra.Command{
+	Use:   "points",
+	Short: "Manages the points of a memory collection",
+	Long:  `Lists, shows and deletes the points of a memory collection`,
+}
+
+var memoryPointsListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "Lists the points of a memory collection",
+	Long:    `Lists the points of a memory collection, one page at a time unless --all is set`,
+	Example: "meow memory points list --collection declarative --limit 20",
+	Args:    cobra.NoArgs,
+	Run:     executeMemoryPointsList,
+}
+
+var memoryPointsGetCmd = &cobra.Command{
+	Use:     "get <point id>",
+	Short:   "Shows a point of a memory collection",
+	Long:    `Shows the full content and metadata of a point of a memory collection`,
+	Example: "meow memory points get 0b5b2ad4-5a8b-4b5d-9d6e-3a9bbd9e4f0e",
+	Args:    cobra.ExactArgs(1),
+	Run:     executeMemoryPointsGet,
+}
+
+var memoryPointsDeleteCmd = &cobra.Command{
+	Use:   "delete [point id...]",
+	Short: "Deletes points of a memory collection",
+	Long:  `Deletes points of a memory collection by id, or all the points ingested from a source with --source`,
+	Example: `meow memory points delete 0b5b2ad4-5a8b-4b5d-9d6e-3a9bbd9e4f0e
+meow memory points delete --source handbook.pdf`,
+	Run: executeMemoryPointsDelete,
+}
+
+var memoryCmdFlags struct {
+	collection       string
+	recallCollection string
+	k                int
+	limit            int
+	offset           string
+	all              bool
+	source           string
+}
+
+func init() {
+	rootCmd.AddCommand(memoryCmd)
+
+	addCatAPIFlags(memoryCmd.PersistentFlags())
+
+	memoryCmd.AddCommand(memoryCollectionsCmd)
+	memoryCmd.AddCommand(memoryRecallCmd)
+	memoryCmd.AddCommand(memoryPointsCmd)
+	memoryPointsCmd.AddCommand(memoryPointsListCmd)
+	memoryPointsCmd.AddCommand(memoryPointsGetCmd)
+	memoryPointsCmd.AddCommand(memoryPointsDeleteCmd)
+
+	memoryRecallCmd.Flags().IntVarP(&memoryCmdFlags.k, "k", "k", 10, "Number of memories recalled for each collection")
+	memoryRecallCmd.Flags().StringVarP(&memoryCmdFlags.recallCollection, "collection", "c", "", "Show only the memories of the collection (default is all)")
+
+	memoryPointsCmd.PersistentFlags().StringVarP(&memoryCmdFlags.collection, "collection", "c", defaultMemoryCollection, "The memory collection")
+	memoryPointsListCmd.Flags().IntVar(&memoryCmdFlags.limit, "limit", 100, "Number of points of each page")
+	memoryPointsListCmd.Flags().StringVar(&memoryCmdFlags.offset, "offset", "", "Offset of the page, as returned by the previous one (default is the first page)")
+	memoryPointsListCmd.Flags().BoolVar(&memoryCmdFlags.all, "all", false, "List all the points, fetching all the pages (default is false)")
+	memoryPointsDeleteCmd.Flags().StringVar(&memoryCmdFlags.source, "source", "", "Delete all the points with this source metadata (default is none)")
+}
+
+// executeMemoryCollections performs the "memory collections" logic.
+func executeMemoryCollections(cmd *cobra.Command, args []string) {
+	catClient, err := resolveCatClient(cmd)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	collections, err := catClient.GetCollections(cmd.Context())
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if globalFlags.json {
+		err = printJSON(collections)
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	table := newTableWriter(os.Stdout)
+	defer table.Flush()
+
+	fmt.Fprintln(table, "NAME\tPOINTS")
+	for _, collection := range collections {
+		fmt.Fprintf(table, "%s\t%d\n", collection.Name, collection.VectorsCount)
+	}
+}
+
+// executeMemoryRecall performs the "memory recall" logic.
+func executeMemoryRecall(cmd *cobra.Command, args []string) {
+	catClient, err := resolveCatClient(cmd)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	result, err := catClient.Recall(cmd.Context(), args[0], memoryCmdFlags.k)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if memoryCmdFlags.recallCollection != "" {
+		memories, exists := result.Vectors.Collections[memoryCmdFlags.recallCollection]
+		if !exists {
+			slog.Error(fmt.Sprintf("collection %q not found", memoryCmdFlags.recallCollection))
+			os.Exit(1)
+		}
+		result.Vectors.Collections = map[string][]cat.RecalledMemory{memoryCmdFlags.recallCollection: memories}
+	}
+
+	if globalFlags.json {
+		err = printJSON(result)
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	collections := make([]string, 0, len(result.Vectors.Collections))
+	for collection := range result.Vectors.Collections {
+		collections = append(collections, collection)
+	}
+	sort.Strings(collections)
+
+	table := newTableWriter(os.Stdout)
+	defer table.Flush()
+
+	fmt.Fprintln(table, "COLLECTION\tSCORE\tSOURCE\tWHEN\tCONTENT")
+	for _, collection := range collections {
+		for _, memory := range result.Vectors.Collections[collection] {
+			fmt.Fprintf(
+				table, "%s\t%.3f\t%s\t%s\t%s\n",
+				collection, memory.Score, memorySource(memory.Metadata), memoryWhen(memory.Metadata), truncate(memory.PageContent, 60),
+			)
+		}
+	}
+}
+
+// executeMemoryPointsList performs the "memory points list" logic.
+func executeMemoryPointsList(cmd *cobra.Command, args []string) {
+	catClient, err := resolveCatClient(cmd)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	page, err := listMemoryPoints(cmd.Context(), catClient)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if globalFlags.json {
+		err = printJSON(page)
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	table := newTableWriter(os.Stdout)
+	fmt.Fprintln(table, "ID\tSOURCE\tWHEN\tCONTENT")
+	for _, point := range page.Points {
+		fmt.Fprintf(
+			table, "%s\t%s\t%s\t%s\n",
+			point.ID, memorySource(point.Payload.Metadata), memoryWhen(point.Payload.Metadata), truncate(point.Payload.PageContent, 60),
+		)
+	}
+	table.Flush()
+
+	if page.NextOffset != "" {
+		slog.Info("More points available", slog.String("next_offset", string(page.NextOffset)))
+	}
+}
+
+// listMemoryPoints returns the requested page of points, or all of them with --all.
+func listMemoryPoints(ctx context.Context, catClient *cat.Client) (*cat.MemoryPointsPage, error) {
+	page, err := catClient.GetPoints(ctx, memoryCmdFlags.collection, memoryCmdFlags.limit, cat.Offset(memoryCmdFlags.offset))
+	if err != nil || !memoryCmdFlags.all {
+		return page, err
+	}
+
+	for page.NextOffset != "" {
+		nextPage, err := catClient.GetPoints(ctx, memoryCmdFlags.collection, memoryCmdFlags.limit, page.NextOffset)
+		if err != nil {
+			return nil, err
+		}
+
+		page.Points = append(page.Points, nextPage.Points...)
+		page.NextOffset = nextPage.NextOffset
+	}
+
+	return page, nil
+}
+
+// executeMemoryPointsGet performs the "memory points get" logic.
+func executeMemoryPointsGet(cmd *cobra.Command, args []string) {
+	catClient, err := resolveCatClient(cmd)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	point, err := findMemoryPoint(cmd.Context(), catClient, memoryCmdFlags.collection, args[0])
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if globalFlags.json {
+		err = printJSON(point)
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	table := newTableWriter(os.Stdout)
+	fmt.Fprintf(table, "ID\t%s\n", point.ID)
+	fmt.Fprintf(table, "COLLECTION\t%s\n", memoryCmdFlags.collection)
+	keys := make([]string, 0, len(point.Payload.Metadata))
+	for key := range point.Payload.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if key == "when" {
+			fmt.Fprintf(table, "%s\t%s\n", key, memoryWhen(point.Payload.Metadata))
+			continue
+		}
+		fmt.Fprintf(table, "%s\t%v\n", key, point.Payload.Metadata[key])
+	}
+	table.Flush()
+
+	fmt.Printf("\n%s\n", point.Payload.PageContent)
+}
+
+// findMemoryPoint looks for the point in all the pages of the collection, since the cat has no endpoint to get a single point.
+func findMemoryPoint(ctx context.Context, catClient *cat.Client, collection string, pointID string) (*cat.MemoryPoint, error) {
+	var offset cat.Offset
+	for {
+		page, err := catClient.GetPoints(ctx, collection, 1000, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		for index := range page.Points {
+			if page.Points[index].ID == pointID {
+				return &page.Points[index], nil
+			}
+		}
+
+		if page.NextOffset == "" {
+			return nil, fmt.Errorf("point %q not found in collection %q", pointID, collection)
+		}
+		offset = page.NextOffset
+	}
+}
+
+// executeMemoryPointsDelete performs the "memory points delete" logic.
+func executeMemoryPointsDelete(cmd *cobra.Command, args []string) {
+	if (len(args) == 0) == (memoryCmdFlags.source == "") {
+		slog.Error("specify either the point ids or the --source flag")
+		os.Exit(1)
+	}
+
+	catClient, err := resolveCatClient(cmd)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if memoryCmdFlags.source != "" {
+		err = catClient.DeletePointsByMetadata(cmd.Context(), memoryCmdFlags.collection, map[string]any{"source": memoryCmdFlags.source})
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		slog.Info("Points deleted", slog.String("collection", memoryCmdFlags.collection), slog.String("source", memoryCmdFlags.source))
+		return
+	}
+
+	for _, pointID := range args {
+		err = catClient.DeletePoint(cmd.Context(), memoryCmdFlags.collection, pointID)
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		slog.Info("Point deleted", slog.String("collection", memoryCmdFlags.collection), slog.String("id", pointID))
+	}
+}
+
+// memorySource returns the source metadata of a memory, or "-" if missing.
+func memorySource(metadata map[string]any) string {
+	source := cast.ToString(metadata["source"])
+	if source == "" {
+		return "-"
+	}
+
+	return truncate(source, 40)
+}
+
+// memoryWhen returns the timestamp metadata (unix seconds) of a memory, or "-" if missing.
+func memoryWhen(metadata map[string]any) string {
+	seconds, err := cast.ToFloat64E(metadata["when"])
+	if err != nil || seconds <= 0 {
+		return "-"
+	}
+
+	return time.Unix(int64(seconds), 0).Local().Format(time.DateTime)
+}
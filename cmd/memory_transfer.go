@@ -0,0 +1,199 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/saniales/meow-cli/pkg/memory"
+)
+
+var memoryExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Exports the memories of the cat to a file",
+	Long: `Exports the points of the memory collections, with their vectors and metadata,
+to a versioned JSON or NDJSON file which can be imported in another cat with the same embedder.
+
+The encoding is NDJSON when the file extension is .ndjson or .jsonl, JSON otherwise,
+unless --format is set. Use --output - to write the memories to stdout.`,
+	Example: `meow memory export --output memories.json
+meow memory export --collection declarative --output declarative.ndjson
+meow memory export --instance dev --output - | gzip > memories.json.gz`,
+	Args: cobra.NoArgs,
+	Run:  executeMemoryExport,
+}
+
+var memoryImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Imports the memories exported from a cat",
+	Long: `Imports the memories of a file written by "meow memory export" or "meow memory wipe".
+
+The embedder name and the vectors dimension of the file must match the ones of the target cat.
+The declarative memories are restored with their ids and vectors, while the points of the
+other collections are created again from their content. The procedural memory is rebuilt
+by the cat from its plugins, so it is skipped.`,
+	Example: `meow memory import memories.json --instance staging
+meow memory import memories.ndjson --collection declarative`,
+	Args: cobra.ExactArgs(1),
+	Run:  executeMemoryImport,
+}
+
+var memoryTransferCmdFlags struct {
+	output       string
+	format       string
+	collections  []string
+	conversation bool
+}
+
+func init() {
+	memoryCmd.AddCommand(memoryExportCmd)
+	memoryCmd.AddCommand(memoryImportCmd)
+
+	memoryExportCmd.Flags().StringVarP(&memoryTransferCmdFlags.output, "output", "o", "", "The exported file, - for stdout (default is memories-<instance>-<timestamp>.json)")
+	memoryExportCmd.Flags().StringVar(&memoryTransferCmdFlags.format, "format", "", "The file encoding, json or ndjson (default is based on the output extension)")
+	memoryExportCmd.Flags().StringSliceVarP(&memoryTransferCmdFlags.collections, "collection", "c", nil, "The collections to export, can be repeated (default is all)")
+	memoryExportCmd.Flags().BoolVar(&memoryTransferCmdFlags.conversation, "conversation", false, "Export the conversation history of the user too (default is false)")
+	memoryImportCmd.Flags().StringSliceVarP(&memoryTransferCmdFlags.collections, "collection", "c", nil, "The collections to import, can be repeated (default is all but procedural)")
+}
+
+// executeMemoryExport performs the "memory export" logic.
+func executeMemoryExport(cmd *cobra.Command, args []string) {
+	instance, err := resolveCatInstance(cmd)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	err = runMemoryExport(cmd.Context(), instance)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+func runMemoryExport(ctx context.Context, instance catInstance) error {
+	catClient, err := newCatClient(instance)
+	if err != nil {
+		return err
+	}
+
+	output := memoryTransferCmdFlags.output
+	if output == "" {
+		output = fmt.Sprintf("memories-%s-%s.json", instance.Name, time.Now().Format("20060102-150405"))
+	}
+	encoding := memory.EncodingFromPath(output)
+	if memoryTransferCmdFlags.format != "" {
+		encoding, err = memory.ParseEncoding(memoryTransferCmdFlags.format)
+		if err != nil {
+			return err
+		}
+	}
+
+	collections := memoryTransferCmdFlags.collections
+	if len(collections) == 0 {
+		available, err := catClient.GetCollections(ctx)
+		if err != nil {
+			return err
+		}
+		for _, collection := range available {
+			collections = append(collections, collection.Name)
+		}
+	}
+
+	snapshot, err := memory.Dump(ctx, catClient, memory.DumpConfig{
+		Instance:     instance.Name,
+		Collections:  collections,
+		Conversation: memoryTransferCmdFlags.conversation,
+	})
+	if err != nil {
+		return err
+	}
+
+	if output == "-" {
+		return snapshot.Write(os.Stdout, encoding)
+	}
+	err = snapshot.WriteFile(output, encoding)
+	if err != nil {
+		return err
+	}
+
+	slog.Info(
+		"Memories exported",
+		slog.String("file", output),
+		slog.String("embedder", snapshot.Embedder.Name),
+		slog.Int("points", snapshot.PointsCount()),
+		slog.Int("messages", len(snapshot.ConversationHistory)),
+	)
+	return nil
+}
+
+// executeMemoryImport performs the "memory import" logic.
+func executeMemoryImport(cmd *cobra.Command, args []string) {
+	catClient, err := resolveCatClient(cmd)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	snapshot, err := memory.ReadFile(args[0])
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	target, err := memory.CurrentEmbedder(cmd.Context(), catClient)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+	err = snapshot.CheckEmbedder(target)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	collections := memoryTransferCmdFlags.collections
+	if len(collections) == 0 {
+		collections = slices.DeleteFunc(snapshot.CollectionNames(), func(collection string) bool {
+			return collection == memory.ProceduralCollection
+		})
+	}
+	if len(snapshot.ConversationHistory) > 0 {
+		slog.Warn("The conversation history cannot be restored through the cat API, skipping it", slog.Int("messages", len(snapshot.ConversationHistory)))
+	}
+
+	results, err := memory.Restore(cmd.Context(), catClient, snapshot, collections)
+	for _, result := range results {
+		slog.Info(
+			"Memories imported",
+			slog.String("collection", result.Collection),
+			slog.Int("points", result.Points),
+			slog.Bool("reembedded", result.Reembedded),
+		)
+	}
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,222 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/saniales/meow-cli/pkg/memory"
+	"github.com/saniales/meow-cli/pkg/providers/cat"
+)
+
+var memoryWipeCmd = &cobra.Command{
+	Use:   "wipe",
+	Short: "Wipes memory collections or the conversation history",
+	Long: `Wipes a memory collection, all of them or the conversation history.
+
+The command asks for confirmation, and refuses to run without a terminal unless --yes is set.
+Before wiping, a snapshot of the affected memories is saved in the meow data folder
+(or --snapshot-dir), so that a collection wipe can be undone with "meow memory import <snapshot>".
+The conversation history cannot be restored through the cat API: its snapshot is only a copy
+for reference, and "meow memory import" skips it.`,
+	Example: `meow memory wipe --collection declarative
+meow memory wipe --all --yes
+meow memory wipe --conversation`,
+	Args: cobra.NoArgs,
+	Run:  executeMemoryWipe,
+}
+
+var memoryWipeCmdFlags struct {
+	collection   string
+	all          bool
+	conversation bool
+	yes          bool
+	snapshotDir  string
+}
+
+func init() {
+	memoryCmd.AddCommand(memoryWipeCmd)
+
+	memoryWipeCmd.Flags().StringVarP(&memoryWipeCmdFlags.collection, "collection", "c", "", "Wipe the specified collection")
+	memoryWipeCmd.Flags().BoolVar(&memoryWipeCmdFlags.all, "all", false, "Wipe all the collections (default is false)")
+	memoryWipeCmd.Flags().BoolVar(&memoryWipeCmdFlags.conversation, "conversation", false, "Wipe the conversation history of the user (default is false)")
+	memoryWipeCmd.Flags().BoolVarP(&memoryWipeCmdFlags.yes, "yes", "y", false, "Do not ask for confirmation (default is false)")
+	memoryWipeCmd.Flags().StringVar(&memoryWipeCmdFlags.snapshotDir, "snapshot-dir", "", "Folder where the pre-wipe snapshot is saved (default is the meow data folder)")
+	memoryWipeCmd.MarkFlagsMutuallyExclusive("collection", "all", "conversation")
+	memoryWipeCmd.MarkFlagsOneRequired("collection", "all", "conversation")
+}
+
+// executeMemoryWipe performs the "memory wipe" logic.
+func executeMemoryWipe(cmd *cobra.Command, args []string) {
+	instance, err := resolveCatInstance(cmd)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	err = runMemoryWipe(cmd.Context(), instance)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+func runMemoryWipe(ctx context.Context, instance catInstance) error {
+	catClient, err := newCatClient(instance)
+	if err != nil {
+		return err
+	}
+
+	dumpConfig := memory.DumpConfig{Instance: instance.Name}
+	target := ""
+	switch {
+	case memoryWipeCmdFlags.conversation:
+		dumpConfig.Conversation = true
+		target = "the conversation history"
+		slog.Warn("The conversation history cannot be restored after the wipe, the snapshot only keeps a copy for reference")
+	case memoryWipeCmdFlags.all:
+		collections, err := catClient.GetCollections(ctx)
+		if err != nil {
+			return err
+		}
+		for _, collection := range collections {
+			dumpConfig.Collections = append(dumpConfig.Collections, collection.Name)
+		}
+		target = "all the collections (" + strings.Join(dumpConfig.Collections, ", ") + ")"
+	default:
+		dumpConfig.Collections = []string{memoryWipeCmdFlags.collection}
+		target = fmt.Sprintf("the %q collection", memoryWipeCmdFlags.collection)
+	}
+
+	err = confirmWipe(fmt.Sprintf("Wipe %s of the %q instance?", target, instance.Name))
+	if err != nil {
+		return err
+	}
+
+	snapshotPath, err := saveWipeSnapshot(ctx, catClient, dumpConfig)
+	if err != nil {
+		return fmt.Errorf("cannot save the snapshot, nothing was wiped: %w", err)
+	}
+
+	switch {
+	case memoryWipeCmdFlags.conversation:
+		err = catClient.WipeConversationHistory(ctx)
+	case memoryWipeCmdFlags.all:
+		err = catClient.WipeCollections(ctx)
+	default:
+		err = catClient.WipeCollection(ctx, memoryWipeCmdFlags.collection)
+	}
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Memory wiped", slog.String("target", target), slog.String("snapshot", snapshotPath))
+	return nil
+}
+
+// confirmWipe asks the user to confirm the wipe, unless --yes is set.
+func confirmWipe(question string) error {
+	if memoryWipeCmdFlags.yes {
+		return nil
+	}
+	if !isInteractive() {
+		return ErrConfirmationRequired("wipe")
+	}
+
+	confirmed, err := promptBool(question, false)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		return errors.New("wipe cancelled")
+	}
+
+	return nil
+}
+
+// saveWipeSnapshot dumps the memories about to be wiped, returning the snapshot path.
+func saveWipeSnapshot(ctx context.Context, catClient *cat.Client, dumpConfig memory.DumpConfig) (string, error) {
+	snapshotDir := memoryWipeCmdFlags.snapshotDir
+	if snapshotDir == "" {
+		var err error
+		snapshotDir, err = dataDir("snapshots", dumpConfig.Instance)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		err := os.MkdirAll(snapshotDir, 0o700)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	slog.Info("Saving a snapshot of the memories before wiping...")
+	snapshot, err := memory.Dump(ctx, catClient, dumpConfig)
+	if err != nil {
+		return "", err
+	}
+
+	file, snapshotPath, err := createSnapshotFile(snapshotDir, "wipe-"+snapshot.CreatedAt.Format("20060102-150405"))
+	if err != nil {
+		return "", err
+	}
+	err = snapshot.Write(file, memory.EncodingJSON)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	slog.Debug(
+		"Snapshot saved",
+		slog.String("path", snapshotPath),
+		slog.Int("points", snapshot.PointsCount()),
+		slog.Int("messages", len(snapshot.ConversationHistory)),
+	)
+
+	return snapshotPath, nil
+}
+
+// createSnapshotFile creates a new JSON file in the folder, never overwriting the existing ones:
+// when name.json already exists (e.g. two wipes in the same second), a numeric suffix is added.
+func createSnapshotFile(folder string, name string) (*os.File, string, error) {
+	for suffix := 1; ; suffix++ {
+		fileName := name + ".json"
+		if suffix > 1 {
+			fileName = fmt.Sprintf("%s-%d.json", name, suffix)
+		}
+
+		filePath := filepath.Join(folder, fileName)
+		file, err := os.OpenFile(filePath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+
+		return file, filePath, nil
+	}
+}
@@ -0,0 +1,55 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateSnapshotFile(t *testing.T) {
+	folder := t.TempDir()
+
+	var paths []string
+	for index := 0; index < 3; index++ {
+		file, filePath, err := createSnapshotFile(folder, "wipe-20240610-100000")
+		if err != nil {
+			t.Fatalf("createSnapshotFile() error = %v", err)
+		}
+		_, err = file.WriteString(filePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		file.Close()
+		paths = append(paths, filePath)
+	}
+
+	want := []string{"wipe-20240610-100000.json", "wipe-20240610-100000-2.json", "wipe-20240610-100000-3.json"}
+	for index, filePath := range paths {
+		if filepath.Base(filePath) != want[index] {
+			t.Errorf("createSnapshotFile() #%d = %q, want %q", index, filepath.Base(filePath), want[index])
+		}
+
+		// each snapshot must keep its own content
+		content, err := os.ReadFile(filePath)
+		if err != nil || string(content) != filePath {
+			t.Errorf("snapshot %q = %q (error %v), want its own content", filePath, content, err)
+		}
+	}
+}
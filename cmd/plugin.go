@@ -0,0 +1,329 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/saniales/meow-cli/pkg/plugin"
+	"github.com/saniales/meow-cli/pkg/providers/cat"
+)
+
+var pluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "Manages the plugins of the cat",
+	Long:  `Manages the plugins of the cat`,
+}
+
+var pluginListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "Lists the installed plugins",
+	Long:    `Lists the installed plugins with their version, active state and upgrade availability`,
+	Example: "meow plugin list --json",
+	Args:    cobra.NoArgs,
+	Run:     executePluginList,
+}
+
+var pluginInstallCmd = &cobra.Command{
+	Use:   "install <folder | archive | git URL> | --registry <name>",
+	Short: "Installs a plugin in the cat",
+	Long: `Installs a plugin in the cat.
+
+The plugin can be a local folder (zipped automatically), a zip or tar archive,
+a git repository URL (cloned with the git command, use #<ref> to select a branch or tag)
+or, with --registry, the name or URL of a plugin in the registry (see "meow plugin search").`,
+	Example: `meow plugin install ./my_plugin
+meow plugin install my_plugin.zip
+meow plugin install https://github.com/user/my_plugin.git#v1.0.0
+meow plugin install --registry "Weather"`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  executePluginInstall,
+}
+
+var pluginInstallCmdFlags struct {
+	registry string
+}
+
+var pluginUninstallCmd = &cobra.Command{
+	Use:     "uninstall <plugin id>",
+	Short:   "Uninstalls a plugin from the cat",
+	Long:    `Uninstalls a plugin from the cat`,
+	Example: "meow plugin uninstall my_plugin",
+	Args:    cobra.ExactArgs(1),
+	Run:     executePluginUninstall,
+}
+
+var pluginEnableCmd = &cobra.Command{
+	Use:     "enable <plugin id>",
+	Short:   "Activates an installed plugin",
+	Long:    `Activates an installed plugin, doing nothing if already active`,
+	Example: "meow plugin enable my_plugin",
+	Args:    cobra.ExactArgs(1),
+	Run:     executePluginToggle(true),
+}
+
+var pluginDisableCmd = &cobra.Command{
+	Use:     "disable <plugin id>",
+	Short:   "Deactivates an installed plugin",
+	Long:    `Deactivates an installed plugin, doing nothing if already inactive`,
+	Example: "meow plugin disable my_plugin",
+	Args:    cobra.ExactArgs(1),
+	Run:     executePluginToggle(false),
+}
+
+func init() {
+	rootCmd.AddCommand(pluginCmd)
+
+	addCatAPIFlags(pluginCmd.PersistentFlags())
+
+	pluginCmd.AddCommand(pluginListCmd)
+	pluginCmd.AddCommand(pluginInstallCmd)
+	pluginInstallCmd.Flags().StringVar(&pluginInstallCmdFlags.registry, "registry", "", "Name or URL of the registry plugin to install (default is none)")
+	pluginCmd.AddCommand(pluginUninstallCmd)
+	pluginCmd.AddCommand(pluginEnableCmd)
+	pluginCmd.AddCommand(pluginDisableCmd)
+}
+
+// executePluginList performs the "plugin list" logic.
+func executePluginList(cmd *cobra.Command, args []string) {
+	catClient, err := resolveCatClient(cmd)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	plugins, err := catClient.ListPlugins(cmd.Context(), "")
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if globalFlags.json {
+		err = printJSON(plugins.Installed)
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	table := newTableWriter(os.Stdout)
+	defer table.Flush()
+
+	fmt.Fprintln(table, "ID\tNAME\tVERSION\tACTIVE\tUPGRADE")
+	for _, installed := range plugins.Installed {
+		upgrade := "-"
+		if installed.Upgrade != "" {
+			upgrade = installed.Upgrade
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%t\t%s\n", installed.ID, installed.Name, installed.Version, installed.Active, upgrade)
+	}
+}
+
+// executePluginInstall performs the "plugin install" logic.
+func executePluginInstall(cmd *cobra.Command, args []string) {
+	catClient, err := resolveCatClient(cmd)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if (len(args) == 0) == (pluginInstallCmdFlags.registry == "") {
+		slog.Error("specify either the plugin source or the --registry flag")
+		os.Exit(1)
+	}
+
+	var result *cat.UploadResult
+	source := pluginInstallCmdFlags.registry
+	if source != "" {
+		var registryPlugin *cat.RegistryPlugin
+		registryPlugin, err = findRegistryPlugin(cmd, source)
+		if err == nil {
+			result, err = installRegistryPlugin(cmd, catClient, registryPlugin)
+		}
+	} else {
+		source = args[0]
+		result, err = installPlugin(cmd.Context(), catClient, source)
+	}
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if globalFlags.json {
+		err = printJSON(result)
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+	slog.Info(result.Info, slog.String("source", source))
+}
+
+// installPlugin uploads the plugin from the source (folder, archive or git URL) to the cat.
+func installPlugin(ctx context.Context, catClient *cat.Client, source string) (*cat.UploadResult, error) {
+	fileName, archive, err := openPluginArchive(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	slog.Debug("Uploading plugin", slog.String("file", fileName))
+	return catClient.UploadPlugin(ctx, fileName, archive)
+}
+
+// openPluginArchive returns the archive of the plugin source, packing folders and git repositories as zip.
+func openPluginArchive(ctx context.Context, source string) (string, io.ReadCloser, error) {
+	if isGitURL(source) {
+		return cloneAndZipPlugin(ctx, source)
+	}
+
+	info, err := os.Stat(source)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if info.IsDir() {
+		absFolder, err := filepath.Abs(source)
+		if err != nil {
+			return "", nil, err
+		}
+
+		return zipPluginFolder(absFolder, filepath.Base(absFolder))
+	}
+
+	file, err := os.Open(source)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return filepath.Base(source), file, nil
+}
+
+// zipPluginFolder packs the plugin folder in an in-memory zip archive.
+func zipPluginFolder(folder string, pluginID string) (string, io.ReadCloser, error) {
+	var archive bytes.Buffer
+	err := plugin.WriteZip(folder, pluginID, &archive, plugin.ZipConfig{})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return pluginID + ".zip", io.NopCloser(&archive), nil
+}
+
+// isGitURL checks whether the plugin source is a git repository URL.
+func isGitURL(source string) bool {
+	return strings.HasPrefix(source, "https://") ||
+		strings.HasPrefix(source, "http://") ||
+		strings.HasPrefix(source, "git@") ||
+		strings.HasPrefix(source, "ssh://")
+}
+
+// cloneAndZipPlugin clones the git repository (URL#ref) in a temporary folder and packs it as zip.
+func cloneAndZipPlugin(ctx context.Context, source string) (string, io.ReadCloser, error) {
+	repositoryURL, ref, _ := strings.Cut(source, "#")
+
+	tempDir, err := os.MkdirTemp("", "meow-plugin-")
+	if err != nil {
+		return "", nil, err
+	}
+	defer os.RemoveAll(tempDir)
+
+	pluginID := strings.TrimSuffix(path.Base(strings.TrimSuffix(repositoryURL, "/")), ".git")
+	cloneFolder := filepath.Join(tempDir, pluginID)
+
+	gitArgs := []string{"clone", "--depth", "1"}
+	if ref != "" {
+		gitArgs = append(gitArgs, "--branch", ref)
+	}
+	gitArgs = append(gitArgs, repositoryURL, cloneFolder)
+
+	slog.Info("Cloning plugin repository...", slog.String("url", repositoryURL), slog.String("ref", ref))
+	gitCmd := exec.CommandContext(ctx, "git", gitArgs...)
+	output, err := gitCmd.CombinedOutput()
+	if errors.Is(err, exec.ErrNotFound) {
+		return "", nil, errors.New("the git command is required to install plugins from git repositories")
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("git clone failed: %w\n%s", err, output)
+	}
+
+	return zipPluginFolder(cloneFolder, pluginID)
+}
+
+// executePluginUninstall performs the "plugin uninstall" logic.
+func executePluginUninstall(cmd *cobra.Command, args []string) {
+	catClient, err := resolveCatClient(cmd)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	err = catClient.DeletePlugin(cmd.Context(), args[0])
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+	slog.Info("Plugin uninstalled", slog.String("plugin", args[0]))
+}
+
+// executePluginToggle returns the "plugin enable" or "plugin disable" logic.
+func executePluginToggle(active bool) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		catClient, err := resolveCatClient(cmd)
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+
+		err = setPluginActive(cmd.Context(), catClient, args[0], active)
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		slog.Info("Plugin updated", slog.String("plugin", args[0]), slog.Bool("active", active))
+	}
+}
+
+// setPluginActive toggles the plugin only if its active state differs from the wanted one.
+func setPluginActive(ctx context.Context, catClient *cat.Client, pluginID string, active bool) error {
+	installed, err := catClient.GetPlugin(ctx, pluginID)
+	if err != nil {
+		return err
+	}
+
+	if installed.Active == active {
+		slog.Debug("Plugin already in the wanted state", slog.String("plugin", pluginID), slog.Bool("active", active))
+		return nil
+	}
+
+	return catClient.TogglePlugin(ctx, pluginID)
+}
@@ -0,0 +1,272 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/saniales/meow-cli/pkg/plugin"
+	"github.com/saniales/meow-cli/pkg/providers/cat"
+	"github.com/saniales/meow-cli/pkg/providers/docker"
+)
+
+var pluginDevCmd = &cobra.Command{
+	Use:   "dev <plugin folder>",
+	Short: "Develops a plugin with automatic reload",
+	Long: `Watches the plugin folder and, on every change, syncs it into the plugins folder
+of the running cat and reloads it.
+
+The plugin is reloaded by deactivating and activating it through the API,
+or by restarting the container when the cat does not know the plugin yet (or with --restart).
+The cat log lines about the plugin, warnings, errors and tracebacks are shown while watching.`,
+	Example: "meow plugin dev ./my_plugin --debounce 1s",
+	Args:    cobra.ExactArgs(1),
+	Run:     executePluginDev,
+}
+
+var pluginDevCmdFlags struct {
+	debounce    time.Duration
+	restart     bool
+	waitTimeout time.Duration
+}
+
+func init() {
+	pluginCmd.AddCommand(pluginDevCmd)
+
+	addCatContainerNameFlag(pluginDevCmd.Flags())
+	pluginDevCmd.Flags().String("plugins-folder", "", "The host folder mounted as the cat plugins folder (default is the one mounted in the container)")
+	pluginDevCmd.Flags().DurationVar(&pluginDevCmdFlags.debounce, "debounce", 500*time.Millisecond, "Time without changes to wait before reloading")
+	pluginDevCmd.Flags().BoolVar(&pluginDevCmdFlags.restart, "restart", false, "Always restart the container instead of reloading the plugin through the API (default is false)")
+	pluginDevCmd.Flags().DurationVar(&pluginDevCmdFlags.waitTimeout, "wait-timeout", 2*time.Minute, "Maximum time to wait for the cat to be ready after a restart")
+}
+
+// executePluginDev performs the "plugin dev" logic.
+func executePluginDev(cmd *cobra.Command, args []string) {
+	instance, err := resolveCatInstance(cmd)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	err = runPluginDev(cmd.Context(), instance, args[0], cmd.Flags().Changed("plugins-folder"))
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+// pluginDevSession holds the state needed to sync and reload the plugin under development.
+type pluginDevSession struct {
+	instance          catInstance
+	dockerClient      *docker.DockerClient
+	catClient         *cat.Client
+	pluginID          string
+	folder            string
+	destinationFolder string
+	// reloadMutex avoids overlapping reloads when changes happen during a restart.
+	reloadMutex sync.Mutex
+}
+
+func runPluginDev(ctx context.Context, instance catInstance, folder string, pluginsFolderFlag bool) error {
+	folder, err := filepath.Abs(folder)
+	if err != nil {
+		return err
+	}
+
+	dockerClient, err := docker.NewDockerClient(nil)
+	if err != nil {
+		return err
+	}
+	defer dockerClient.Close()
+
+	status, err := dockerClient.InspectCatContainer(ctx, instance.ContainerName)
+	if err != nil {
+		return err
+	}
+	if !status.Running {
+		return ErrCatNotRunning(instance.ContainerName)
+	}
+
+	pluginsFolder := instance.PluginsFolder
+	if !pluginsFolderFlag && status.PluginsFolder != "" {
+		pluginsFolder = status.PluginsFolder
+	}
+	pluginsFolder, err = filepath.Abs(pluginsFolder)
+	if err != nil {
+		return err
+	}
+
+	catClient, err := newCatClient(instance)
+	if err != nil {
+		return err
+	}
+
+	session := &pluginDevSession{
+		instance:          instance,
+		dockerClient:      dockerClient,
+		catClient:         catClient,
+		pluginID:          filepath.Base(folder),
+		folder:            folder,
+		destinationFolder: filepath.Join(pluginsFolder, filepath.Base(folder)),
+	}
+
+	go session.tailLogs(ctx)
+
+	session.syncAndReload(ctx, nil)
+
+	slog.Info("Watching the plugin for changes, press Ctrl+C to stop", slog.String("folder", folder))
+	return plugin.Watch(ctx, folder, pluginDevCmdFlags.debounce, func(changed []string) {
+		session.syncAndReload(ctx, changed)
+	})
+}
+
+// syncAndReload copies the plugin into the cat plugins folder and reloads it.
+//
+// Errors are logged instead of returned, to keep watching after a failed reload.
+func (session *pluginDevSession) syncAndReload(ctx context.Context, changed []string) {
+	session.reloadMutex.Lock()
+	defer session.reloadMutex.Unlock()
+
+	if len(changed) > 0 {
+		slog.Info("Plugin changed", slog.String("files", strings.Join(changed, ", ")))
+	}
+
+	if session.destinationFolder != session.folder {
+		synced, err := plugin.SyncFolder(session.folder, session.destinationFolder)
+		if err != nil {
+			slog.Error("Cannot sync the plugin", slog.String("error", err.Error()))
+			return
+		}
+		if len(synced) == 0 && len(changed) > 0 {
+			slog.Debug("No content changes to sync")
+			return
+		}
+		slog.Debug("Plugin synced", slog.String("destination", session.destinationFolder), slog.Int("files", len(synced)))
+	}
+
+	err := session.reload(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("Cannot reload the plugin", slog.String("error", err.Error()))
+		}
+		return
+	}
+	slog.Info("Plugin reloaded", slog.String("plugin", session.pluginID))
+}
+
+// reload toggles the plugin through the API, falling back to a container restart
+// when the cat has not discovered the plugin yet.
+func (session *pluginDevSession) reload(ctx context.Context) error {
+	if !pluginDevCmdFlags.restart {
+		installed, err := session.catClient.GetPlugin(ctx, session.pluginID)
+		if err == nil {
+			if installed.Active {
+				err = session.catClient.TogglePlugin(ctx, session.pluginID)
+				if err != nil {
+					return err
+				}
+			}
+
+			return session.catClient.TogglePlugin(ctx, session.pluginID)
+		}
+		if !errors.Is(err, cat.ErrNotFound) {
+			return err
+		}
+		slog.Debug("Plugin not found by the cat, restarting the container", slog.String("plugin", session.pluginID))
+	}
+
+	slog.Info("Restarting the cat...", slog.String("container", session.instance.ContainerName))
+	err := session.dockerClient.RestartCatContainer(ctx, session.instance.ContainerName)
+	if err != nil {
+		return err
+	}
+
+	return session.dockerClient.WaitCatReady(ctx, docker.WaitCatReadyConfig{
+		CatContainerName: session.instance.ContainerName,
+		URL:              session.instance.BaseURL(),
+		Timeout:          pluginDevCmdFlags.waitTimeout,
+		InitialBackoff:   500 * time.Millisecond,
+		MaxBackoff:       5 * time.Second,
+	})
+}
+
+// tailLogs shows the cat log lines relevant to the plugin until the context is done,
+// resuming the stream when the container restarts.
+func (session *pluginDevSession) tailLogs(ctx context.Context) {
+	filter := &pluginLogFilter{pluginID: session.pluginID}
+	catLogger := newCatLogger(os.Stdout)
+	recordWriter := &lineWriter{onLine: func(line string) {
+		if filter.Relevant(line) {
+			logCatRecord(ctx, catLogger, docker.ParseCatLogLine(line))
+		}
+	}}
+
+	since := time.Now()
+	for ctx.Err() == nil {
+		err := session.dockerClient.StreamCatLogs(ctx, session.instance.ContainerName, docker.StreamCatLogsConfig{
+			Follow: true,
+			Since:  since.Format(time.RFC3339Nano),
+			Tail:   "all",
+		}, recordWriter, recordWriter)
+		recordWriter.Flush()
+		if err != nil && ctx.Err() == nil {
+			slog.Debug("Log stream interrupted", slog.String("error", err.Error()))
+		}
+		since = time.Now()
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// pluginLogFilter selects the cat log lines about the plugin, the warnings, the errors and the tracebacks.
+type pluginLogFilter struct {
+	pluginID    string
+	inTraceback bool
+}
+
+// Relevant checks whether the log line should be shown.
+func (filter *pluginLogFilter) Relevant(line string) bool {
+	if strings.HasPrefix(line, "Traceback") {
+		filter.inTraceback = true
+		return true
+	}
+	if filter.inTraceback {
+		// the traceback ends with the first non indented line, the exception message
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			filter.inTraceback = false
+		}
+		return true
+	}
+
+	if strings.Contains(line, filter.pluginID) {
+		return true
+	}
+
+	return docker.ParseCatLogLine(line).Level >= slog.LevelWarn
+}
@@ -0,0 +1,144 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/saniales/meow-cli/pkg/plugin"
+)
+
+var pluginLintCmd = &cobra.Command{
+	Use:   "lint [plugin folder]",
+	Short: "Checks a plugin for common problems",
+	Long: `Checks a plugin for common problems before installing it in the cat:
+plugin.json fields and version format, required files, Python syntax
+(with a tokenizer level check, Python is not needed), duplicated hooks,
+unpinned requirements and requirements conflicting with the cat core.
+
+The command fails if any error is found, or any warning with --strict.`,
+	Example: `meow plugin lint ./my_plugin
+meow plugin lint --strict --json`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  executePluginLint,
+}
+
+var pluginLintCmdFlags struct {
+	strict           bool
+	coreRequirements string
+}
+
+func init() {
+	pluginCmd.AddCommand(pluginLintCmd)
+
+	pluginLintCmd.Flags().BoolVar(&pluginLintCmdFlags.strict, "strict", false, "Fail on warnings too (default is false)")
+	pluginLintCmd.Flags().StringVar(&pluginLintCmdFlags.coreRequirements, "core-requirements", "", "Path of a requirements.txt file with the packages pinned by the cat core (default is the cat v1.7 ones)")
+}
+
+// executePluginLint performs the "plugin lint" logic.
+func executePluginLint(cmd *cobra.Command, args []string) {
+	folder := "."
+	if len(args) > 0 {
+		folder = args[0]
+	}
+
+	err := runPluginLint(folder)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+func runPluginLint(folder string) error {
+	config := plugin.LintConfig{}
+	if pluginLintCmdFlags.coreRequirements != "" {
+		coreRequirements, err := readCoreRequirements(pluginLintCmdFlags.coreRequirements)
+		if err != nil {
+			return err
+		}
+		config.CoreRequirements = coreRequirements
+	}
+
+	issues, err := plugin.Lint(folder, config)
+	if err != nil {
+		return err
+	}
+
+	if globalFlags.json {
+		if issues == nil {
+			issues = []plugin.Issue{}
+		}
+		err = printJSON(issues)
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, issue := range issues {
+			fmt.Println(issue)
+		}
+	}
+
+	counts := make(map[plugin.Severity]int)
+	for _, issue := range issues {
+		counts[issue.Severity]++
+	}
+
+	blocking := counts[plugin.SeverityError]
+	if pluginLintCmdFlags.strict {
+		blocking += counts[plugin.SeverityWarning]
+	}
+	if blocking > 0 {
+		return ErrLintFailed(blocking)
+	}
+
+	if !globalFlags.json {
+		slog.Info(
+			"Plugin checked",
+			slog.String("folder", folder),
+			slog.Int("warnings", counts[plugin.SeverityWarning]),
+			slog.Int("infos", counts[plugin.SeverityInfo]),
+		)
+	}
+	return nil
+}
+
+// readCoreRequirements reads the packages pinned by the cat core from a requirements.txt file.
+func readCoreRequirements(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	requirements, err := plugin.ParseRequirements(file)
+	if err != nil {
+		return nil, err
+	}
+
+	coreRequirements := make(map[string]string, len(requirements))
+	for _, requirement := range requirements {
+		if version, pinned := requirement.Pinned(); pinned {
+			coreRequirements[requirement.Name] = version
+		}
+	}
+
+	return coreRequirements, nil
+}
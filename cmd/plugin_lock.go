@@ -0,0 +1,400 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/saniales/meow-cli/pkg/plugin"
+	"github.com/saniales/meow-cli/pkg/providers/cat"
+)
+
+// corePluginID is the id of the plugin shipped with the cat, never managed by the lockfile.
+const corePluginID = "core_plugin"
+
+var pluginSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Installs, upgrades and removes plugins to match the lockfile",
+	Long: `Makes the plugins of the active instance match the lockfile (meow-plugins.lock):
+missing plugins are installed, plugins with a different version are upgraded,
+plugins not in the lockfile are removed and the active state is applied.
+
+Archives are verified against the lockfile checksums before being uploaded.`,
+	Example: `meow plugin sync --dry-run
+meow plugin sync --lockfile team-plugins.lock --keep-unlisted`,
+	Args: cobra.NoArgs,
+	Run:  executePluginSync,
+}
+
+var pluginFreezeCmd = &cobra.Command{
+	Use:   "freeze",
+	Short: "Writes the installed plugins to the lockfile",
+	Long: `Writes the plugins installed in the active instance, with their versions, sources,
+checksums and active state, to the lockfile (meow-plugins.lock).
+
+The sources of the plugins already in the lockfile are kept, the other ones are looked up
+in the registry, falling back to the plugin URL of their manifest.`,
+	Example: "meow plugin freeze --lockfile team-plugins.lock",
+	Args:    cobra.NoArgs,
+	Run:     executePluginFreeze,
+}
+
+var pluginLockCmdFlags struct {
+	lockfile       string
+	dryRun         bool
+	keepUnlisted   bool
+	installTimeout time.Duration
+}
+
+func init() {
+	pluginCmd.AddCommand(pluginSyncCmd)
+	pluginCmd.AddCommand(pluginFreezeCmd)
+
+	for _, lockCmd := range []*cobra.Command{pluginSyncCmd, pluginFreezeCmd} {
+		lockCmd.Flags().StringVar(&pluginLockCmdFlags.lockfile, "lockfile", plugin.LockfileName, "Path of the plugin lockfile")
+	}
+	pluginSyncCmd.Flags().BoolVar(&pluginLockCmdFlags.dryRun, "dry-run", false, "Only show the changes, without applying them (default is false)")
+	pluginSyncCmd.Flags().BoolVar(&pluginLockCmdFlags.keepUnlisted, "keep-unlisted", false, "Do not remove the plugins missing from the lockfile (default is false)")
+	pluginSyncCmd.Flags().DurationVar(&pluginLockCmdFlags.installTimeout, "install-timeout", 2*time.Minute, "Maximum time to wait for each plugin installation")
+}
+
+// pluginSyncAction represents a change needed to match the lockfile.
+type pluginSyncAction struct {
+	Action string `json:"action"`
+	ID     string `json:"id"`
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+}
+
+// executePluginSync performs the "plugin sync" logic.
+func executePluginSync(cmd *cobra.Command, args []string) {
+	actions, err := runPluginSync(cmd)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if globalFlags.json {
+		if actions == nil {
+			actions = []pluginSyncAction{}
+		}
+		err = printJSON(actions)
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+	if len(actions) == 0 {
+		slog.Info("Plugins already in sync with the lockfile", slog.String("lockfile", pluginLockCmdFlags.lockfile))
+	}
+}
+
+func runPluginSync(cmd *cobra.Command) ([]pluginSyncAction, error) {
+	ctx := cmd.Context()
+
+	lockfile, err := plugin.ReadLockfile(pluginLockCmdFlags.lockfile)
+	if err != nil {
+		return nil, err
+	}
+
+	catClient, err := resolveCatClient(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	plugins, err := catClient.ListPlugins(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	actions := planPluginSync(plugins.Installed, lockfile, !pluginLockCmdFlags.keepUnlisted)
+	for _, action := range actions {
+		attrs := []any{slog.String("plugin", action.ID)}
+		if action.From != "" {
+			attrs = append(attrs, slog.String("from", action.From))
+		}
+		if action.To != "" {
+			attrs = append(attrs, slog.String("to", action.To))
+		}
+
+		if pluginLockCmdFlags.dryRun {
+			slog.Info("Would "+action.Action+" plugin", attrs...)
+			continue
+		}
+
+		slog.Info(strings.ToUpper(action.Action[:1])+action.Action[1:]+" plugin", attrs...)
+		err = applyPluginSyncAction(cmd, catClient, lockfile, action)
+		if err != nil {
+			return actions, fmt.Errorf("cannot %s plugin %q: %w", action.Action, action.ID, err)
+		}
+	}
+
+	return actions, nil
+}
+
+// planPluginSync returns the changes needed to make the installed plugins match the lockfile.
+func planPluginSync(installed []cat.Plugin, lockfile *plugin.Lockfile, removeUnlisted bool) []pluginSyncAction {
+	installedByID := make(map[string]cat.Plugin, len(installed))
+	for _, installedPlugin := range installed {
+		installedByID[installedPlugin.ID] = installedPlugin
+	}
+
+	var actions []pluginSyncAction
+	for _, locked := range lockfile.Plugins {
+		installedPlugin, isInstalled := installedByID[locked.ID]
+		switch {
+		case !isInstalled:
+			actions = append(actions, pluginSyncAction{Action: "install", ID: locked.ID, To: locked.Version})
+		case locked.Version != "" && installedPlugin.Version != locked.Version:
+			actions = append(actions, pluginSyncAction{Action: "upgrade", ID: locked.ID, From: installedPlugin.Version, To: locked.Version})
+		case installedPlugin.Active != locked.Active && locked.Active:
+			actions = append(actions, pluginSyncAction{Action: "enable", ID: locked.ID})
+		case installedPlugin.Active != locked.Active:
+			actions = append(actions, pluginSyncAction{Action: "disable", ID: locked.ID})
+		}
+	}
+
+	if removeUnlisted {
+		for _, installedPlugin := range installed {
+			if _, isLocked := lockfile.Find(installedPlugin.ID); !isLocked && installedPlugin.ID != corePluginID {
+				actions = append(actions, pluginSyncAction{Action: "remove", ID: installedPlugin.ID, From: installedPlugin.Version})
+			}
+		}
+	}
+
+	return actions
+}
+
+// applyPluginSyncAction performs the change on the cat.
+func applyPluginSyncAction(cmd *cobra.Command, catClient *cat.Client, lockfile *plugin.Lockfile, action pluginSyncAction) error {
+	ctx := cmd.Context()
+	if action.Action == "remove" {
+		return catClient.DeletePlugin(ctx, action.ID)
+	}
+
+	locked, _ := lockfile.Find(action.ID)
+	if action.Action == "install" || action.Action == "upgrade" {
+		err := installLockedPlugin(cmd, catClient, locked)
+		if err != nil {
+			return err
+		}
+
+		err = waitPluginInstalled(ctx, catClient, locked, pluginLockCmdFlags.installTimeout)
+		if err != nil {
+			return err
+		}
+	}
+
+	return setPluginActive(ctx, catClient, locked.ID, locked.Active)
+}
+
+// installLockedPlugin uploads the locked plugin to the cat, after verifying its checksum.
+func installLockedPlugin(cmd *cobra.Command, catClient *cat.Client, locked *plugin.LockedPlugin) error {
+	fileName, archive, registryPlugin, err := readLockedPluginArchive(cmd, locked)
+	if err != nil {
+		return err
+	}
+
+	if archive == nil {
+		if locked.SHA256 != "" {
+			slog.Warn("The checksum cannot be verified when installing from the registry through the cat, configure registry_url to verify it", slog.String("plugin", locked.ID))
+		}
+		_, err = catClient.InstallPluginFromRegistry(cmd.Context(), registryPlugin.URL)
+		return err
+	}
+
+	checksum := sha256Hex(archive)
+	if locked.SHA256 != "" && !strings.EqualFold(locked.SHA256, checksum) {
+		return ErrChecksumMismatch(locked.ID, locked.SHA256, checksum)
+	}
+
+	_, err = catClient.UploadPlugin(cmd.Context(), fileName, bytes.NewReader(archive))
+	return err
+}
+
+// readLockedPluginArchive returns the archive of the locked plugin source.
+//
+// For registry sources without a configured registry_url the archive is nil,
+// since the cat downloads the returned registry plugin by itself.
+func readLockedPluginArchive(cmd *cobra.Command, locked *plugin.LockedPlugin) (string, []byte, *cat.RegistryPlugin, error) {
+	if locked.Source == "" {
+		return "", nil, nil, fmt.Errorf("plugin %q has no source in the lockfile", locked.ID)
+	}
+
+	name, isRegistry := strings.CutPrefix(locked.Source, plugin.RegistrySourcePrefix)
+	if !isRegistry {
+		fileName, archiveReader, err := openPluginArchive(cmd.Context(), locked.Source)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		defer archiveReader.Close()
+
+		archive, err := io.ReadAll(archiveReader)
+		return fileName, archive, nil, err
+	}
+
+	registryPlugin, err := findRegistryPlugin(cmd, name)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	registryClient, err := resolveRegistryClient(cmd)
+	if err != nil || registryClient == nil {
+		return "", nil, registryPlugin, err
+	}
+
+	archive, err := registryClient.Download(cmd.Context(), registryPlugin.URL)
+	return locked.ID + ".zip", archive, registryPlugin, err
+}
+
+// waitPluginInstalled waits until the cat loads the locked plugin, since the installation is asynchronous.
+func waitPluginInstalled(ctx context.Context, catClient *cat.Client, locked *plugin.LockedPlugin, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		installed, err := catClient.GetPlugin(ctx, locked.ID)
+		if err == nil && (locked.Version == "" || installed.Version == locked.Version) {
+			return nil
+		}
+		if err == nil {
+			err = fmt.Errorf("installed version is %s, the lockfile expects %s", installed.Version, locked.Version)
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("the plugin was not installed within %s: %w", timeout, err)
+			}
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// executePluginFreeze performs the "plugin freeze" logic.
+func executePluginFreeze(cmd *cobra.Command, args []string) {
+	lockfile, err := runPluginFreeze(cmd)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if globalFlags.json {
+		err = printJSON(lockfile)
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+	slog.Info("Lockfile written", slog.String("lockfile", pluginLockCmdFlags.lockfile), slog.Int("plugins", len(lockfile.Plugins)))
+}
+
+func runPluginFreeze(cmd *cobra.Command) (*plugin.Lockfile, error) {
+	catClient, err := resolveCatClient(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	plugins, err := catClient.ListPlugins(cmd.Context(), "")
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := plugin.ReadLockfile(pluginLockCmdFlags.lockfile)
+	if errors.Is(err, os.ErrNotExist) {
+		previous, err = new(plugin.Lockfile), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	lockfile := new(plugin.Lockfile)
+	for _, installedPlugin := range plugins.Installed {
+		if installedPlugin.ID == corePluginID {
+			continue
+		}
+
+		locked := plugin.LockedPlugin{ID: installedPlugin.ID}
+		if previousLocked, exists := previous.Find(installedPlugin.ID); exists {
+			locked = *previousLocked
+		}
+		if locked.Version != installedPlugin.Version {
+			locked.SHA256 = ""
+		}
+		locked.Version = installedPlugin.Version
+		locked.Active = installedPlugin.Active
+
+		if locked.Source == "" {
+			locked.Source = guessPluginSource(cmd, installedPlugin)
+		}
+		if locked.Source == "" {
+			slog.Warn("Cannot find the plugin source, set it in the lockfile before syncing", slog.String("plugin", locked.ID))
+		} else if locked.SHA256 == "" {
+			_, archive, _, err := readLockedPluginArchive(cmd, &locked)
+			if err != nil {
+				slog.Warn("Cannot compute the plugin checksum", slog.String("plugin", locked.ID), slog.String("error", err.Error()))
+			} else if archive != nil {
+				locked.SHA256 = sha256Hex(archive)
+			}
+		}
+
+		lockfile.Plugins = append(lockfile.Plugins, locked)
+	}
+
+	err = lockfile.Write(pluginLockCmdFlags.lockfile)
+	if err != nil {
+		return nil, err
+	}
+
+	return lockfile, nil
+}
+
+// guessPluginSource looks up the installed plugin in the registry, falling back to its plugin URL.
+func guessPluginSource(cmd *cobra.Command, installedPlugin cat.Plugin) string {
+	registryPlugin, err := findRegistryPlugin(cmd, installedPlugin.Name)
+	if err == nil {
+		return plugin.RegistrySourcePrefix + registryPlugin.URL
+	}
+	slog.Debug("Plugin not found in the registry", slog.String("plugin", installedPlugin.ID), slog.String("error", err.Error()))
+
+	if isGitURL(installedPlugin.PluginURL) {
+		return installedPlugin.PluginURL
+	}
+
+	return ""
+}
+
+// sha256Hex returns the hex encoded SHA-256 checksum of the data.
+func sha256Hex(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
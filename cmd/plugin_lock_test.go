@@ -0,0 +1,115 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/saniales/meow-cli/pkg/plugin"
+	"github.com/saniales/meow-cli/pkg/providers/cat"
+)
+
+func TestPlanPluginSync(t *testing.T) {
+	tests := []struct {
+		name           string
+		installed      []cat.Plugin
+		locked         []plugin.LockedPlugin
+		removeUnlisted bool
+		want           []pluginSyncAction
+	}{
+		{
+			name: "in sync",
+			installed: []cat.Plugin{
+				{ID: corePluginID, Version: "0.0.1", Active: true},
+				{ID: "weather", Version: "1.0.0", Active: true},
+			},
+			locked:         []plugin.LockedPlugin{{ID: "weather", Version: "1.0.0", Active: true}},
+			removeUnlisted: true,
+		},
+		{
+			name:      "install",
+			installed: []cat.Plugin{{ID: corePluginID, Active: true}},
+			locked:    []plugin.LockedPlugin{{ID: "weather", Version: "1.0.0", Active: true}},
+			want:      []pluginSyncAction{{Action: "install", ID: "weather", To: "1.0.0"}},
+		},
+		{
+			name:      "upgrade",
+			installed: []cat.Plugin{{ID: "weather", Version: "0.9.0", Active: true}},
+			locked:    []plugin.LockedPlugin{{ID: "weather", Version: "1.0.0", Active: true}},
+			want:      []pluginSyncAction{{Action: "upgrade", ID: "weather", From: "0.9.0", To: "1.0.0"}},
+		},
+		{
+			name:      "unversioned lock does not upgrade",
+			installed: []cat.Plugin{{ID: "weather", Version: "0.9.0", Active: true}},
+			locked:    []plugin.LockedPlugin{{ID: "weather", Active: true}},
+		},
+		{
+			name:      "enable",
+			installed: []cat.Plugin{{ID: "weather", Version: "1.0.0", Active: false}},
+			locked:    []plugin.LockedPlugin{{ID: "weather", Version: "1.0.0", Active: true}},
+			want:      []pluginSyncAction{{Action: "enable", ID: "weather"}},
+		},
+		{
+			name:      "disable",
+			installed: []cat.Plugin{{ID: "weather", Version: "1.0.0", Active: true}},
+			locked:    []plugin.LockedPlugin{{ID: "weather", Version: "1.0.0", Active: false}},
+			want:      []pluginSyncAction{{Action: "disable", ID: "weather"}},
+		},
+		{
+			name: "remove unlisted, skipping the core plugin",
+			installed: []cat.Plugin{
+				{ID: corePluginID, Version: "0.0.1", Active: true},
+				{ID: "old_plugin", Version: "0.1.0", Active: true},
+			},
+			removeUnlisted: true,
+			want:           []pluginSyncAction{{Action: "remove", ID: "old_plugin", From: "0.1.0"}},
+		},
+		{
+			name:      "keep unlisted",
+			installed: []cat.Plugin{{ID: "old_plugin", Version: "0.1.0", Active: true}},
+		},
+		{
+			name: "lockfile order, removals last",
+			installed: []cat.Plugin{
+				{ID: "old_plugin", Version: "0.1.0"},
+				{ID: "b", Version: "1.0.0", Active: true},
+			},
+			locked: []plugin.LockedPlugin{
+				{ID: "b", Version: "2.0.0", Active: true},
+				{ID: "a", Version: "1.0.0"},
+			},
+			removeUnlisted: true,
+			want: []pluginSyncAction{
+				{Action: "upgrade", ID: "b", From: "1.0.0", To: "2.0.0"},
+				{Action: "install", ID: "a", To: "1.0.0"},
+				{Action: "remove", ID: "old_plugin", From: "0.1.0"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lockfile := &plugin.Lockfile{Version: 1, Plugins: test.locked}
+			got := planPluginSync(test.installed, lockfile, test.removeUnlisted)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("planPluginSync() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/saniales/meow-cli/pkg/plugin"
+)
+
+var pluginNewCmd = &cobra.Command{
+	Use:   "new [name]",
+	Short: "Generates a new plugin skeleton",
+	Long: `Generates a new plugin skeleton with plugin.json, requirements.txt, hooks, tools and settings modules,
+a README and a test file.
+
+Missing values are asked interactively when running in a terminal.`,
+	Example: `meow plugin new
+meow plugin new "My Plugin" --author "Jane Doe" --forms
+meow plugin new my_plugin --no-hooks --no-tools --output ./plugins/my_plugin`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  executePluginNew,
+}
+
+var pluginNewCmdFlags struct {
+	author      string
+	description string
+	output      string
+	noHooks     bool
+	noTools     bool
+	forms       bool
+}
+
+func init() {
+	pluginCmd.AddCommand(pluginNewCmd)
+
+	pluginNewCmd.Flags().StringVar(&pluginNewCmdFlags.author, "author", "", "Author of the plugin (default is asked interactively)")
+	pluginNewCmd.Flags().StringVar(&pluginNewCmdFlags.description, "description", "", "Description of the plugin (default is asked interactively)")
+	pluginNewCmd.Flags().StringVarP(&pluginNewCmdFlags.output, "output", "o", "", "Folder where the plugin is generated (default is ./<plugin id>)")
+	pluginNewCmd.Flags().BoolVar(&pluginNewCmdFlags.noHooks, "no-hooks", false, "Do not include the hook examples (default is false)")
+	pluginNewCmd.Flags().BoolVar(&pluginNewCmdFlags.noTools, "no-tools", false, "Do not include the tool example (default is false)")
+	pluginNewCmd.Flags().BoolVar(&pluginNewCmdFlags.forms, "forms", false, "Include the form example (default is false)")
+}
+
+// executePluginNew performs the "plugin new" logic.
+func executePluginNew(cmd *cobra.Command, args []string) {
+	config, err := resolveScaffoldConfig(cmd, args)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	folder := pluginNewCmdFlags.output
+	if folder == "" {
+		folder = plugin.PluginID(config.Name)
+	}
+
+	created, err := plugin.Scaffold(folder, config)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if globalFlags.json {
+		err = printJSON(map[string]any{"folder": folder, "files": created})
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	for _, file := range created {
+		slog.Debug("File created", slog.String("file", filepath.Join(folder, file)))
+	}
+	slog.Info("Plugin created", slog.String("name", config.Name), slog.String("folder", folder))
+}
+
+// resolveScaffoldConfig builds the skeleton values from the flags, asking the missing ones when interactive.
+func resolveScaffoldConfig(cmd *cobra.Command, args []string) (plugin.ScaffoldConfig, error) {
+	config := plugin.ScaffoldConfig{
+		Author:      pluginNewCmdFlags.author,
+		Description: pluginNewCmdFlags.description,
+		Hooks:       !pluginNewCmdFlags.noHooks,
+		Tools:       !pluginNewCmdFlags.noTools,
+		Forms:       pluginNewCmdFlags.forms,
+	}
+	if len(args) > 0 {
+		config.Name = args[0]
+	}
+
+	if !isInteractive() {
+		if config.Name == "" {
+			return config, fmt.Errorf("the plugin name is required when not running in a terminal")
+		}
+		return config, nil
+	}
+
+	var err error
+	if config.Name == "" {
+		config.Name, err = promptString("Plugin name", "")
+		if err != nil {
+			return config, err
+		}
+	}
+	if !cmd.Flags().Changed("author") {
+		config.Author, err = promptString("Author", "")
+		if err != nil {
+			return config, err
+		}
+	}
+	if !cmd.Flags().Changed("description") {
+		config.Description, err = promptString("Description", "A Cheshire Cat plugin")
+		if err != nil {
+			return config, err
+		}
+	}
+	if !cmd.Flags().Changed("no-hooks") {
+		config.Hooks, err = promptBool("Include the hook examples?", true)
+		if err != nil {
+			return config, err
+		}
+	}
+	if !cmd.Flags().Changed("no-tools") {
+		config.Tools, err = promptBool("Include the tool example?", true)
+		if err != nil {
+			return config, err
+		}
+	}
+	if !cmd.Flags().Changed("forms") {
+		config.Forms, err = promptBool("Include the form example?", false)
+		if err != nil {
+			return config, err
+		}
+	}
+
+	return config, nil
+}
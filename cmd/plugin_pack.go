@@ -0,0 +1,141 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/saniales/meow-cli/pkg/plugin"
+)
+
+var pluginPackCmd = &cobra.Command{
+	Use:   "pack [plugin folder]",
+	Short: "Builds a reproducible release archive of a plugin",
+	Long: `Builds a reproducible zip archive of the plugin, with sorted entries and fixed timestamps,
+and writes its SHA-256 checksum next to it (<archive>.sha256).
+
+The files matching the patterns in the plugin .gitignore and .meowignore files are excluded.
+The version can be stamped into the archived plugin.json with --version or --version-from-git
+(the plugin folder is not modified).`,
+	Example: `meow plugin pack ./my_plugin
+meow plugin pack --version-from-git --output dist/my_plugin.zip`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  executePluginPack,
+}
+
+var pluginPackCmdFlags struct {
+	output         string
+	version        string
+	versionFromGit bool
+}
+
+func init() {
+	pluginCmd.AddCommand(pluginPackCmd)
+
+	pluginPackCmd.Flags().StringVarP(&pluginPackCmdFlags.output, "output", "o", "", "Path of the archive (default is ./<plugin id>-<version>.zip)")
+	pluginPackCmd.Flags().StringVar(&pluginPackCmdFlags.version, "version", "", "Version stamped into the archived plugin.json (default is the plugin.json one)")
+	pluginPackCmd.Flags().BoolVar(&pluginPackCmdFlags.versionFromGit, "version-from-git", false, "Stamp the version of the latest git tag, without the v prefix (default is false)")
+	pluginPackCmd.MarkFlagsMutuallyExclusive("version", "version-from-git")
+}
+
+// executePluginPack performs the "plugin pack" logic.
+func executePluginPack(cmd *cobra.Command, args []string) {
+	folder := "."
+	if len(args) > 0 {
+		folder = args[0]
+	}
+
+	result, err := runPluginPack(cmd.Context(), folder)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if globalFlags.json {
+		err = printJSON(result)
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+	slog.Info(
+		"Plugin packed",
+		slog.String("archive", result.Archive),
+		slog.String("version", result.Version),
+		slog.String("sha256", result.SHA256),
+	)
+}
+
+func runPluginPack(ctx context.Context, folder string) (*plugin.PackResult, error) {
+	folder, err := filepath.Abs(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	version := pluginPackCmdFlags.version
+	if pluginPackCmdFlags.versionFromGit {
+		version, err = gitTagVersion(ctx, folder)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	output := pluginPackCmdFlags.output
+	if output == "" {
+		manifest, err := plugin.ReadManifest(folder)
+		if err != nil {
+			return nil, err
+		}
+
+		outputVersion := version
+		if outputVersion == "" {
+			outputVersion = manifest.Version
+		}
+		output = fmt.Sprintf("%s-%s.zip", filepath.Base(folder), outputVersion)
+	}
+
+	return plugin.Pack(folder, plugin.PackConfig{
+		Output:  output,
+		Version: version,
+	})
+}
+
+// gitTagVersion returns the latest git tag reachable from HEAD in the folder, without the v prefix.
+func gitTagVersion(ctx context.Context, folder string) (string, error) {
+	gitCmd := exec.CommandContext(ctx, "git", "describe", "--tags", "--abbrev=0")
+	gitCmd.Dir = folder
+
+	output, err := gitCmd.Output()
+	if errors.Is(err, exec.ErrNotFound) {
+		return "", errors.New("the git command is required to read the version from the git tags")
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot read the version from the git tags: %w", err)
+	}
+
+	return strings.TrimPrefix(strings.TrimSpace(string(output)), "v"), nil
+}
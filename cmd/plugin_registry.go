@@ -0,0 +1,181 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/saniales/meow-cli/pkg/providers/cat"
+)
+
+var pluginSearchCmd = &cobra.Command{
+	Use:   "search [query]",
+	Short: "Searches the plugin registry",
+	Long: `Searches the community plugins in the Cheshire Cat registry, listing all of them without a query.
+
+The registry is queried through the cat, unless registry_url is configured
+(or --registry-url is passed), in which case it is queried directly.`,
+	Example: `meow plugin search weather
+meow plugin search --registry-url http://localhost:8000 weather`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  executePluginSearch,
+}
+
+func init() {
+	pluginCmd.AddCommand(pluginSearchCmd)
+
+	pluginCmd.PersistentFlags().String("registry-url", "", "The plugin registry URL queried directly (default is through the cat)")
+}
+
+// executePluginSearch performs the "plugin search" logic.
+func executePluginSearch(cmd *cobra.Command, args []string) {
+	query := ""
+	if len(args) > 0 {
+		query = args[0]
+	}
+
+	plugins, err := searchRegistry(cmd, query)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if globalFlags.json {
+		if plugins == nil {
+			plugins = []cat.RegistryPlugin{}
+		}
+		err = printJSON(plugins)
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	table := newTableWriter(os.Stdout)
+	defer table.Flush()
+
+	fmt.Fprintln(table, "NAME\tAUTHOR\tVERSION\tDESCRIPTION")
+	for _, registryPlugin := range plugins {
+		fmt.Fprintf(
+			table, "%s\t%s\t%s\t%s\n",
+			registryPlugin.Name, registryPlugin.AuthorName, registryPlugin.Version,
+			truncate(registryPlugin.Description, 60),
+		)
+	}
+}
+
+// searchRegistry returns the registry plugins matching the query,
+// querying the configured registry directly or through the cat.
+func searchRegistry(cmd *cobra.Command, query string) ([]cat.RegistryPlugin, error) {
+	registryClient, err := resolveRegistryClient(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if registryClient != nil {
+		return registryClient.Search(cmd.Context(), query)
+	}
+
+	catClient, err := resolveCatClient(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	plugins, err := catClient.ListPlugins(cmd.Context(), query)
+	if err != nil {
+		return nil, err
+	}
+
+	return plugins.Registry, nil
+}
+
+// findRegistryPlugin returns the registry plugin with the specified name (case insensitive) or URL.
+func findRegistryPlugin(cmd *cobra.Command, name string) (*cat.RegistryPlugin, error) {
+	// the registry search is by text, URLs are matched in the full list
+	query := name
+	if strings.Contains(name, "://") {
+		query = ""
+	}
+
+	plugins, err := searchRegistry(cmd, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []cat.RegistryPlugin
+	for _, registryPlugin := range plugins {
+		if strings.EqualFold(registryPlugin.Name, name) ||
+			registryPlugin.URL == name ||
+			(registryPlugin.PluginURL != "" && registryPlugin.PluginURL == name) ||
+			strings.EqualFold(path.Base(registryPlugin.URL), name) {
+			matches = append(matches, registryPlugin)
+		}
+	}
+
+	switch len(matches) {
+	case 1:
+		return &matches[0], nil
+	case 0:
+		names := make([]string, 0, len(plugins))
+		for _, registryPlugin := range plugins {
+			names = append(names, registryPlugin.Name)
+		}
+		if len(names) == 0 {
+			return nil, fmt.Errorf("no registry plugin matches %q", name)
+		}
+		return nil, fmt.Errorf("no registry plugin named %q, similar plugins: %s", name, strings.Join(names, ", "))
+	default:
+		urls := make([]string, 0, len(matches))
+		for _, registryPlugin := range matches {
+			urls = append(urls, registryPlugin.URL)
+		}
+		return nil, fmt.Errorf("%d registry plugins are named %q, use the URL to select one: %s", len(matches), name, strings.Join(urls, ", "))
+	}
+}
+
+// installRegistryPlugin installs the registry plugin in the cat, downloading it from the
+// configured registry or letting the cat download it.
+func installRegistryPlugin(cmd *cobra.Command, catClient *cat.Client, registryPlugin *cat.RegistryPlugin) (*cat.UploadResult, error) {
+	registryClient, err := resolveRegistryClient(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info(
+		"Installing plugin from the registry...",
+		slog.String("name", registryPlugin.Name),
+		slog.String("version", registryPlugin.Version),
+		slog.String("url", registryPlugin.URL),
+	)
+	if registryClient == nil {
+		return catClient.InstallPluginFromRegistry(cmd.Context(), registryPlugin.URL)
+	}
+
+	archive, err := registryClient.Download(cmd.Context(), registryPlugin.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	return catClient.UploadPlugin(cmd.Context(), path.Base(registryPlugin.URL)+".zip", bytes.NewReader(archive))
+}
@@ -0,0 +1,240 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/saniales/meow-cli/pkg/providers/cat"
+	"github.com/saniales/meow-cli/pkg/schema"
+)
+
+var pluginSettingsCmd = &cobra.Command{
+	Use:   "settings <plugin id> get|set|edit|reset [key=value...]",
+	Short: "Manages the settings of a plugin",
+	Long: `Manages the settings of a plugin, validating them against the plugin settings schema before sending.
+
+Actions:
+  get [key]          prints the current settings, or only the specified one
+  set key=value...   updates the specified settings (or the ones in the --file JSON/YAML file)
+  edit               opens the current settings in $EDITOR
+  reset              restores the default settings declared by the plugin`,
+	Example: `meow plugin settings my_plugin get
+meow plugin settings my_plugin set temperature=0.5 enabled=true
+meow plugin settings my_plugin set --file settings.yaml
+meow plugin settings my_plugin edit`,
+	Args: cobra.MinimumNArgs(2),
+	Run:  executePluginSettings,
+}
+
+var pluginSettingsCmdFlags struct {
+	file string
+}
+
+func init() {
+	pluginCmd.AddCommand(pluginSettingsCmd)
+
+	pluginSettingsCmd.Flags().StringVarP(&pluginSettingsCmdFlags.file, "file", "f", "", "JSON or YAML file with the settings to set (default is none)")
+}
+
+// executePluginSettings performs the "plugin settings" logic.
+func executePluginSettings(cmd *cobra.Command, args []string) {
+	catClient, err := resolveCatClient(cmd)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	err = runPluginSettings(cmd.Context(), catClient, args[0], args[1], args[2:])
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+// runPluginSettings dispatches the settings action of the plugin.
+func runPluginSettings(ctx context.Context, catClient *cat.Client, pluginID string, action string, actionArgs []string) error {
+	settings, err := catClient.GetPluginSettings(ctx, pluginID)
+	if err != nil {
+		return err
+	}
+
+	settingsSchema, err := schema.Parse(settings.Schema)
+	if err != nil {
+		return fmt.Errorf("cannot parse the settings schema of plugin %q: %w", pluginID, err)
+	}
+
+	var value map[string]any
+	switch action {
+	case "get":
+		if len(actionArgs) > 1 {
+			return fmt.Errorf("get accepts at most one setting key, got %d", len(actionArgs))
+		}
+		return printPluginSettings(settings.Value, actionArgs)
+	case "set":
+		value, err = mergePluginSettings(settingsSchema, settings.Value, actionArgs)
+	case "edit":
+		value, err = editPluginSettings(settingsSchema, settings.Value)
+	case "reset":
+		value = settingsSchema.Defaults()
+	default:
+		return fmt.Errorf("unknown settings action %q, use one of get, set, edit or reset", action)
+	}
+	if err != nil {
+		return err
+	}
+
+	problems := settingsSchema.Validate(value)
+	if len(problems) > 0 {
+		return ErrInvalidPluginSettings(pluginID, problems)
+	}
+
+	updated, err := catClient.UpdatePluginSettings(ctx, pluginID, value)
+	if err != nil {
+		return err
+	}
+
+	if globalFlags.json {
+		return printJSON(updated.Value)
+	}
+	slog.Info("Plugin settings updated", slog.String("plugin", pluginID), slog.String("action", action))
+	return nil
+}
+
+// printPluginSettings prints all the settings, or the selected one, as YAML (or JSON with --json).
+func printPluginSettings(value map[string]any, keys []string) error {
+	var output any = value
+	if len(keys) == 1 {
+		setting, exists := value[keys[0]]
+		if !exists {
+			return fmt.Errorf("setting %q is not set", keys[0])
+		}
+		output = setting
+	}
+
+	if globalFlags.json {
+		return printJSON(output)
+	}
+
+	encoder := yaml.NewEncoder(os.Stdout)
+	defer encoder.Close()
+	encoder.SetIndent(2)
+
+	return encoder.Encode(output)
+}
+
+// mergePluginSettings applies the key=value pairs, or the --file content, on top of the current settings.
+func mergePluginSettings(settingsSchema *schema.Schema, current map[string]any, pairs []string) (map[string]any, error) {
+	merged := make(map[string]any, len(current))
+	for key, value := range current {
+		merged[key] = value
+	}
+
+	if pluginSettingsCmdFlags.file != "" {
+		fileValue, err := readSettingsFile(pluginSettingsCmdFlags.file)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range fileValue {
+			merged[key] = value
+		}
+	}
+
+	if len(pairs) == 0 && pluginSettingsCmdFlags.file == "" {
+		return nil, fmt.Errorf("set requires key=value pairs or the --file flag")
+	}
+
+	for _, pair := range pairs {
+		key, rawValue, found := strings.Cut(pair, "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid setting %q, use the key=value format", pair)
+		}
+
+		value, err := settingsSchema.ParseValue(key, rawValue)
+		if err != nil {
+			return nil, err
+		}
+		merged[key] = value
+	}
+
+	return merged, nil
+}
+
+// readSettingsFile reads the settings from a JSON or YAML file (JSON is valid YAML).
+func readSettingsFile(path string) (map[string]any, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	value := make(map[string]any)
+	err = yaml.Unmarshal(content, &value)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse the settings file %q: %w", path, err)
+	}
+
+	return value, nil
+}
+
+// editPluginSettings opens the current settings in the user editor and returns the edited ones.
+func editPluginSettings(settingsSchema *schema.Schema, current map[string]any) (map[string]any, error) {
+	tempDir, err := os.MkdirTemp("", "meow-settings-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tempDir)
+
+	var content bytes.Buffer
+	for _, name := range settingsSchema.PropertyNames() {
+		property, _ := settingsSchema.Property(name)
+		fmt.Fprintf(&content, "# %s (%s)", name, strings.Join(property.Types(settingsSchema), " | "))
+		if property.Description != "" {
+			fmt.Fprintf(&content, ": %s", property.Description)
+		}
+		content.WriteString("\n")
+	}
+	if len(current) > 0 {
+		encoded, err := yaml.Marshal(current)
+		if err != nil {
+			return nil, err
+		}
+		content.Write(encoded)
+	}
+
+	path := filepath.Join(tempDir, "settings.yaml")
+	err = os.WriteFile(path, content.Bytes(), 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	err = openEditor(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return readSettingsFile(path)
+}
@@ -19,12 +19,14 @@ along with this program. If not, see <http://www.gnu.org/licenses/>.
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
 	"runtime"
 	"runtime/debug"
 	"strings"
@@ -36,8 +38,6 @@ import (
 	"github.com/saniales/meow-cli/pkg/providers/install"
 )
 
-var cfgFile string
-
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "meow",
@@ -60,6 +60,7 @@ var globalFlags struct {
 	verbose    bool
 	quiet      bool
 	json       bool
+	instance   string
 }
 
 var versionCmd = &cobra.Command{
@@ -85,7 +86,10 @@ var installCmdFlags struct {
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
-	err := rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err := rootCmd.ExecuteContext(ctx)
 	if err != nil {
 		os.Exit(1)
 	}
@@ -119,6 +123,7 @@ func init() {
 	rootCmd.PersistentFlags().BoolVarP(&globalFlags.verbose, "verbose", "v", false, "Enable verbose output (default is false) - Incompatible with --quiet")
 	rootCmd.PersistentFlags().BoolVarP(&globalFlags.quiet, "quiet", "q", false, "Enables output only on errors (default is false) - Incompatible with --verbose")
 	rootCmd.PersistentFlags().BoolVar(&globalFlags.json, "json", false, "Enables JSON formatted output (default is false)")
+	rootCmd.PersistentFlags().StringVar(&globalFlags.instance, "instance", "", "The cat instance profile to use (default is the current instance in the config file)")
 
 	// install flags
 	installCmd.Flags().BoolVar(&installCmdFlags.reinstall, "reinstall", false, "Force install even if (default is false)")
@@ -127,9 +132,9 @@ func init() {
 
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
-	if cfgFile != "" {
+	if globalFlags.configFile != "" {
 		// Use config file from the flag.
-		viper.SetConfigFile(cfgFile)
+		viper.SetConfigFile(globalFlags.configFile)
 	} else {
 		// Find home directory.
 		home, err := os.UserHomeDir()
@@ -0,0 +1,171 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/saniales/meow-cli/pkg/providers/cat"
+	"github.com/saniales/meow-cli/pkg/providers/docker"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Reports the state of the cat container and API",
+	Long: `Reports the state of the cat container and API.
+
+It shows the container state, uptime, image tag and digest, bound host port,
+mounted folders and the version reported by the cat API.
+Use the global --json flag for machine-readable output.`,
+	Example: "meow status --json",
+	Run:     executeStatus,
+}
+
+// catAPIStatus represents the state of the cat HTTP API.
+type catAPIStatus struct {
+	URL       string `json:"url"`
+	Reachable bool   `json:"reachable"`
+	Version   string `json:"version,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// statusOutput represents the output of the status command.
+type statusOutput struct {
+	*docker.CatContainerStatus
+	Uptime string       `json:"uptime,omitempty"`
+	API    catAPIStatus `json:"api"`
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+
+	addCatContainerNameFlag(statusCmd.Flags())
+}
+
+// fetchCatStatus returns the status reported by the cat API, waiting at most 5 seconds.
+func fetchCatStatus(ctx context.Context, instance catInstance) (*cat.Status, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	catClient, err := newCatClient(instance)
+	if err != nil {
+		return nil, err
+	}
+
+	return catClient.Status(ctx)
+}
+
+// executeStatus performs the "status" logic.
+func executeStatus(cmd *cobra.Command, args []string) {
+	instance, err := resolveCatInstance(cmd)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	status, err := runStatus(cmd.Context(), instance)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if globalFlags.json {
+		err = printJSON(status)
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	printStatus(status)
+}
+
+func runStatus(ctx context.Context, instance catInstance) (*statusOutput, error) {
+	dockerClient, err := docker.NewDockerClient(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer dockerClient.Close()
+
+	containerStatus, err := dockerClient.InspectCatContainer(ctx, instance.ContainerName)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &statusOutput{
+		CatContainerStatus: containerStatus,
+		API: catAPIStatus{
+			URL: instance.BaseURL(),
+		},
+	}
+	if containerStatus.Running {
+		status.Uptime = containerStatus.Uptime.String()
+	}
+	if containerStatus.HostPort != 0 && instance.APIURL == "" {
+		instance.Port = containerStatus.HostPort
+		status.API.URL = instance.BaseURL()
+	}
+
+	catStatus, err := fetchCatStatus(ctx, instance)
+	if err != nil {
+		status.API.Error = err.Error()
+	} else {
+		status.API.Reachable = true
+		status.API.Version = catStatus.Version
+	}
+
+	return status, nil
+}
+
+func printStatus(status *statusOutput) {
+	table := newTableWriter(os.Stdout)
+	defer table.Flush()
+
+	fmt.Fprintf(table, "Container:\t%s\n", status.ContainerName)
+	fmt.Fprintf(table, "State:\t%s\n", status.State)
+	if !status.Exists {
+		fmt.Fprintf(table, "API:\t%s\n", apiStateText(status.API))
+		return
+	}
+
+	if status.Running && status.StartedAt != nil {
+		fmt.Fprintf(table, "Uptime:\t%s (since %s)\n", status.Uptime, status.StartedAt.Local().Format(time.RFC1123))
+	}
+	fmt.Fprintf(table, "Image:\t%s\n", status.Image)
+	fmt.Fprintf(table, "Image digest:\t%s\n", status.ImageDigest)
+	fmt.Fprintf(table, "Host port:\t%d\n", status.HostPort)
+	fmt.Fprintf(table, "Plugins folder:\t%s\n", status.PluginsFolder)
+	fmt.Fprintf(table, "Data folder:\t%s\n", status.DataFolder)
+	fmt.Fprintf(table, "Static folder:\t%s\n", status.StaticFolder)
+	fmt.Fprintf(table, "API:\t%s\n", apiStateText(status.API))
+}
+
+func apiStateText(api catAPIStatus) string {
+	if !api.Reachable {
+		return fmt.Sprintf("unreachable at %s (%s)", api.URL, api.Error)
+	}
+
+	return fmt.Sprintf("reachable at %s, cat version %s", api.URL, api.Version)
+}
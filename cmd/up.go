@@ -0,0 +1,159 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/saniales/meow-cli/pkg/providers/docker"
+)
+
+var upCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Starts the cat container on the current machine",
+	Long: `Starts the cat container on the current machine.
+
+The cat image is pulled if missing, then the container is created and started
+with the plugins, data and static folders mounted from the host.
+
+The command waits until the cat API answers, unless --wait-timeout is 0.`,
+	Example: "meow up --port 1865 --plugins-folder ./plugins",
+	Run:     executeUp,
+}
+
+var upCmdFlags struct {
+	pull           bool
+	waitTimeout    time.Duration
+	waitBackoff    time.Duration
+	waitMaxBackoff time.Duration
+}
+
+func init() {
+	rootCmd.AddCommand(upCmd)
+
+	addCatInstanceFlags(upCmd.Flags())
+	upCmd.Flags().BoolVar(&upCmdFlags.pull, "pull", false, "Always pull the cat image, even if already present (default is false)")
+	upCmd.Flags().DurationVar(&upCmdFlags.waitTimeout, "wait-timeout", 2*time.Minute, "Maximum time to wait for the cat API to be ready, 0 disables the wait")
+	upCmd.Flags().DurationVar(&upCmdFlags.waitBackoff, "wait-backoff", 500*time.Millisecond, "Initial delay between readiness checks, doubled on each retry")
+	upCmd.Flags().DurationVar(&upCmdFlags.waitMaxBackoff, "wait-max-backoff", 5*time.Second, "Maximum delay between readiness checks")
+}
+
+// executeUp performs the "up" logic.
+func executeUp(cmd *cobra.Command, args []string) {
+	instance, err := resolveCatInstance(cmd)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	err = runUp(cmd.Context(), instance)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+func runUp(ctx context.Context, instance catInstance) error {
+	dockerClient, err := docker.NewDockerClient(pullProgressFunc)
+	if err != nil {
+		return err
+	}
+	defer dockerClient.Close()
+
+	exists, running, err := dockerClient.CatContainerExists(ctx, instance.ContainerName)
+	if err != nil {
+		return err
+	}
+	if running {
+		slog.Info("The cat is already running", slog.String("container", instance.ContainerName))
+		return nil
+	}
+	if exists {
+		slog.Debug("Removing stopped cat container", slog.String("container", instance.ContainerName))
+		err = dockerClient.RemoveCatContainer(ctx, instance.ContainerName)
+		if err != nil {
+			return err
+		}
+	}
+
+	imageExists, err := dockerClient.CatImageExists(ctx, instance.FullImage())
+	if err != nil {
+		return err
+	}
+	if upCmdFlags.pull || !imageExists {
+		slog.Info("Pulling the cat image...", slog.String("image", instance.FullImage()))
+		err = dockerClient.PullCatImage(ctx, instance.FullImage())
+		if err != nil {
+			return err
+		}
+	}
+
+	err = instance.prepareFolders()
+	if err != nil {
+		return err
+	}
+
+	slog.Info(
+		"Starting the cat...",
+		slog.String("container", instance.ContainerName),
+		slog.String("image", instance.FullImage()),
+		slog.Int("port", instance.Port),
+	)
+	err = dockerClient.StartCatContainer(ctx, docker.StartCatContainerConfig{
+		CatImage:              instance.FullImage(),
+		CatContainerName:      instance.ContainerName,
+		CatContainerBoundPort: instance.Port,
+		PluginFolderPath:      instance.PluginsFolder,
+		DataFolderPath:        instance.DataFolder,
+		StaticFolderPath:      instance.StaticFolder,
+	})
+	if err != nil {
+		return err
+	}
+
+	if upCmdFlags.waitTimeout > 0 {
+		slog.Info("Waiting for the cat to be ready...", slog.String("url", instance.BaseURL()))
+		err = dockerClient.WaitCatReady(ctx, docker.WaitCatReadyConfig{
+			CatContainerName: instance.ContainerName,
+			URL:              instance.BaseURL(),
+			Timeout:          upCmdFlags.waitTimeout,
+			InitialBackoff:   upCmdFlags.waitBackoff,
+			MaxBackoff:       upCmdFlags.waitMaxBackoff,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	slog.Info("The cat is up", slog.String("container", instance.ContainerName), slog.String("url", instance.BaseURL()))
+
+	return nil
+}
+
+// pullProgressFunc logs the docker pull progress messages.
+func pullProgressFunc(message docker.PullMessage) {
+	if message.ID == "" {
+		slog.Info(message.Status)
+		return
+	}
+	slog.Debug(message.Status, slog.String("layer", message.ID), slog.String("progress", message.Progress))
+}
@@ -0,0 +1,111 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+
+	"github.com/spf13/cobra"
+)
+
+var useCmd = &cobra.Command{
+	Use:   "use [instance]",
+	Short: "Selects the cat instance profile used by the other commands",
+	Long: `Selects the cat instance profile used by the other commands.
+
+Instance profiles are defined in the config file under the "instances" key:
+
+  current_instance: dev
+  instances:
+    dev:
+      port: 1865
+    staging:
+      port: 1866
+      container_name: cat-staging
+      api_url: https://staging.example.com
+      api_key: secret
+
+Without arguments it lists the defined instances, marking the active one.
+The active instance can be overridden with the global --instance flag or the CCAT_INSTANCE env variable.`,
+	Example: "meow use staging",
+	Args:    cobra.MaximumNArgs(1),
+	Run:     executeUse,
+}
+
+func init() {
+	rootCmd.AddCommand(useCmd)
+}
+
+// executeUse performs the "use" logic.
+func executeUse(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		err := printInstances()
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	name := args[0]
+	if !instanceExists(name) {
+		slog.Error(ErrInstanceNotDefined(name).Error())
+		os.Exit(1)
+	}
+
+	err := updateConfigFile(func(settings map[string]any) error {
+		setNestedValue(settings, "current_instance", name)
+		return nil
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+	slog.Info("Switched instance", slog.String("instance", name))
+}
+
+func printInstances() error {
+	active := activeInstanceName()
+	names := configuredInstanceNames()
+	if !slices.Contains(names, defaultInstanceName) {
+		names = append([]string{defaultInstanceName}, names...)
+	}
+
+	if globalFlags.json {
+		return printJSON(struct {
+			Current   string   `json:"current"`
+			Instances []string `json:"instances"`
+		}{
+			Current:   active,
+			Instances: names,
+		})
+	}
+
+	for _, name := range names {
+		marker := " "
+		if name == active {
+			marker = "*"
+		}
+		fmt.Printf("%s %s\n", marker, name)
+	}
+
+	return nil
+}
@@ -19,7 +19,7 @@
 go install -a -v github.com/go-bindata/go-bindata/...@latest
 
 # Generate bindata assets
-go-bindata -nomemcopy -pkg bindata -o ./gen/bindata/bindata.go ./install-scripts/...
+go-bindata -nomemcopy -pkg bindata -o ./gen/bindata/bindata.go ./install-scripts/... ./plugin-templates/...
 
 # get latest slug from github actions env variable, or defaults to commit hash
 if [ -z "$GITHUB_REF_NAME" ]; then
@@ -0,0 +1,228 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+// Package backup archives and restores the folders of a cat instance.
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// ManifestName is the name of the manifest, the last entry of the backup archives.
+const ManifestName = "manifest.json"
+
+// formatVersion is the version of the backup archives written by this CLI.
+const formatVersion = 1
+
+// Folders are the names of the instance folders, used as top level folders of the archives.
+var Folders = []string{"plugins", "data", "static"}
+
+// Manifest describes the content of a backup archive.
+type Manifest struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Instance  string    `json:"instance"`
+	// Settings are the effective settings of the instance, secrets excluded.
+	Settings    map[string]any `json:"settings"`
+	Image       string         `json:"image"`
+	ImageDigest string         `json:"image_digest,omitempty"`
+	Files       []File         `json:"files"`
+}
+
+// File represents an archived file, with its checksum.
+type File struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Config represents the instance to back up.
+type Config struct {
+	Instance    string
+	Settings    map[string]any
+	Image       string
+	ImageDigest string
+	// FolderPaths are the host paths of the instance folders, indexed by folder name (see Folders).
+	FolderPaths map[string]string
+}
+
+// Create writes the backup archive of the instance folders to output, replacing it only once fully written.
+//
+// Missing folders are archived as empty.
+func Create(output string, config Config) (*Manifest, error) {
+	manifest := &Manifest{
+		Version:     formatVersion,
+		CreatedAt:   time.Now().UTC(),
+		Instance:    config.Instance,
+		Settings:    config.Settings,
+		Image:       config.Image,
+		ImageDigest: config.ImageDigest,
+		Files:       []File{},
+	}
+
+	temporaryFile, err := os.CreateTemp(filepath.Dir(output), ".meow-backup-*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(temporaryFile.Name())
+	defer temporaryFile.Close()
+
+	compressor := gzip.NewWriter(temporaryFile)
+	archive := tar.NewWriter(compressor)
+	for _, folder := range Folders {
+		err = archiveFolder(archive, folder, config.FolderPaths[folder], manifest)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	err = archive.WriteHeader(&tar.Header{
+		Name:     ManifestName,
+		Mode:     0o644,
+		Size:     int64(len(content)),
+		ModTime:  manifest.CreatedAt,
+		Typeflag: tar.TypeReg,
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, err = archive.Write(content)
+	if err != nil {
+		return nil, err
+	}
+
+	err = archive.Close()
+	if err != nil {
+		return nil, err
+	}
+	err = compressor.Close()
+	if err != nil {
+		return nil, err
+	}
+	err = temporaryFile.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	// the archives contain the cat data, so they are readable only by the user
+	err = os.Chmod(temporaryFile.Name(), 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest, os.Rename(temporaryFile.Name(), output)
+}
+
+// archiveFolder adds the folder content to the archive under the folder name, recording the files checksums.
+func archiveFolder(archive *tar.Writer, name string, folder string, manifest *Manifest) error {
+	err := archive.WriteHeader(&tar.Header{
+		Name:     name + "/",
+		Mode:     0o755,
+		ModTime:  manifest.CreatedAt,
+		Typeflag: tar.TypeDir,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = os.Stat(folder)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return filepath.WalkDir(folder, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if filePath == folder {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(folder, filePath)
+		if err != nil {
+			return err
+		}
+		archivePath := path.Join(name, filepath.ToSlash(relativePath))
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		link := ""
+		if info.Mode()&fs.ModeSymlink != 0 {
+			link, err = os.Readlink(filePath)
+			if err != nil {
+				return err
+			}
+		} else if !info.Mode().IsRegular() && !info.IsDir() {
+			// sockets, pipes and devices cannot be restored
+			return nil
+		}
+
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = archivePath
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		// the owner names of the host are meaningless where the backup is restored
+		header.Uname = ""
+		header.Gname = ""
+
+		err = archive.WriteHeader(header)
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		hash := sha256.New()
+		size, err := io.Copy(archive, io.TeeReader(file, hash))
+		if err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, File{Path: archivePath, Size: size, SHA256: hex.EncodeToString(hash.Sum(nil))})
+
+		return nil
+	})
+}
@@ -0,0 +1,36 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package backup
+
+import (
+	"fmt"
+)
+
+// ErrFolderNotEmpty is returned when a backup would be restored over existing files.
+func ErrFolderNotEmpty(folder string) error {
+	return fmt.Errorf("the folder %q is not empty, use --force to overwrite it", folder)
+}
+
+// ErrCorruptedArchive is returned when the content of a backup does not match its manifest.
+func ErrCorruptedArchive(archive string, reason string) error {
+	return fmt.Errorf("the backup %q is corrupted: %s", archive, reason)
+}
+
+// ErrUnsafeSymlink is returned when a backup entry would create or follow a symbolic link out of its instance folder.
+func ErrUnsafeSymlink(name string, reason string) error {
+	return fmt.Errorf("unsafe symbolic link %q: %s", name, reason)
+}
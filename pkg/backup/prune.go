@@ -0,0 +1,100 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ArchiveExtension is the extension of the backup archives.
+const ArchiveExtension = ".tar.gz"
+
+// TimestampLayout is the layout of the creation time, in local time, in the names of the scheduled archives (<instance>-<timestamp>.tar.gz).
+const TimestampLayout = "20060102-150405"
+
+// PruneConfig represents the retention policy of the backups of an instance.
+type PruneConfig struct {
+	// Prefix selects the archives of the folder to prune, named <prefix><timestamp>.tar.gz.
+	Prefix string
+	// Keep is the number of most recent archives always kept (0 keeps all of them).
+	Keep int
+	// MaxAge removes the archives older than it (0 disables the check).
+	MaxAge time.Duration
+}
+
+// Prune deletes the old archives of the folder according to the retention policy,
+// returning the paths of the deleted ones.
+//
+// The archives are ordered by the creation time in their names rather than by their modification time,
+// which changes when they are copied or touched.
+// The most recent archive is never deleted, so that a misconfigured policy cannot remove all the backups.
+func Prune(folder string, config PruneConfig, now time.Time) ([]string, error) {
+	entries, err := os.ReadDir(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	type archive struct {
+		path      string
+		createdAt time.Time
+	}
+	archives := make([]archive, 0, len(entries))
+	for _, entry := range entries {
+		timestamp, found := strings.CutPrefix(entry.Name(), config.Prefix)
+		if !found || !entry.Type().IsRegular() {
+			continue
+		}
+		timestamp, found = strings.CutSuffix(timestamp, ArchiveExtension)
+		if !found {
+			continue
+		}
+		createdAt, err := time.ParseInLocation(TimestampLayout, timestamp, time.Local)
+		if err != nil {
+			continue
+		}
+
+		archives = append(archives, archive{path: filepath.Join(folder, entry.Name()), createdAt: createdAt})
+	}
+	sort.SliceStable(archives, func(i, j int) bool {
+		return archives[i].createdAt.After(archives[j].createdAt)
+	})
+
+	deleted := make([]string, 0)
+	for index, archive := range archives {
+		if index == 0 {
+			continue
+		}
+
+		tooMany := config.Keep > 0 && index >= config.Keep
+		tooOld := config.MaxAge > 0 && now.Sub(archive.createdAt) > config.MaxAge
+		if !tooMany && !tooOld {
+			continue
+		}
+
+		err = os.Remove(archive.path)
+		if err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, archive.path)
+	}
+
+	return deleted, nil
+}
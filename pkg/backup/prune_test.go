@@ -0,0 +1,95 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestPrune(t *testing.T) {
+	now := time.Date(2024, time.June, 10, 12, 0, 0, 0, time.Local)
+	archiveName := func(age time.Duration) string {
+		return "default-" + now.Add(-age).Format(TimestampLayout) + ArchiveExtension
+	}
+	// the archives are listed from the newest to the oldest
+	archives := []string{archiveName(time.Hour), archiveName(25 * time.Hour), archiveName(49 * time.Hour), archiveName(73 * time.Hour)}
+	others := []string{
+		"staging-" + now.Format(TimestampLayout) + ArchiveExtension,
+		"default-latest" + ArchiveExtension,
+		"default-" + now.Format(TimestampLayout) + ".zip",
+	}
+
+	tests := []struct {
+		name   string
+		config PruneConfig
+		want   []string
+	}{
+		{name: "no policy", config: PruneConfig{Prefix: "default-"}, want: []string{}},
+		{name: "keep", config: PruneConfig{Prefix: "default-", Keep: 2}, want: archives[2:]},
+		{name: "keep more than the archives", config: PruneConfig{Prefix: "default-", Keep: 10}, want: []string{}},
+		{name: "max age", config: PruneConfig{Prefix: "default-", MaxAge: 48 * time.Hour}, want: archives[2:]},
+		{name: "keep and max age", config: PruneConfig{Prefix: "default-", Keep: 3, MaxAge: 48 * time.Hour}, want: archives[2:]},
+		{name: "newest always kept", config: PruneConfig{Prefix: "default-", MaxAge: time.Minute}, want: archives[1:]},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			folder := t.TempDir()
+			for index, name := range append(append([]string{}, archives...), others...) {
+				filePath := filepath.Join(folder, name)
+				err := os.WriteFile(filePath, []byte(name), 0o600)
+				if err != nil {
+					t.Fatal(err)
+				}
+				// the modification times are the opposite of the creation times, as after copying the archives
+				modTime := now.Add(time.Duration(index) * time.Minute)
+				err = os.Chtimes(filePath, modTime, modTime)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			deleted, err := Prune(folder, test.config, now)
+			if err != nil {
+				t.Fatalf("Prune() error = %v", err)
+			}
+
+			got := make([]string, 0, len(deleted))
+			for _, filePath := range deleted {
+				got = append(got, filepath.Base(filePath))
+			}
+			want := append([]string{}, test.want...)
+			sort.Strings(got)
+			sort.Strings(want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Prune() deleted %v, want %v", got, want)
+			}
+
+			for _, name := range got {
+				if _, err := os.Stat(filepath.Join(folder, name)); !os.IsNotExist(err) {
+					t.Errorf("Prune() deleted %s, but it still exists", name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,310 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// RestoreConfig represents where and how a backup is restored.
+type RestoreConfig struct {
+	// FolderPaths are the host paths of the instance folders, indexed by folder name (see Folders).
+	FolderPaths map[string]string
+	// Force clears the folders which are not empty, instead of failing.
+	Force bool
+}
+
+// Verify reads the whole archive, checking its files against the checksums of the manifest,
+// and its paths and symbolic links against the instance folders.
+func Verify(archivePath string) (*Manifest, error) {
+	var manifest *Manifest
+	checksums := map[string]string{}
+
+	err := walkArchive(archivePath, func(header *tar.Header, reader io.Reader) error {
+		if header.Name == ManifestName {
+			manifest = new(Manifest)
+			return json.NewDecoder(reader).Decode(manifest)
+		}
+
+		_, _, err := checkEntry(header)
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			return nil
+		}
+
+		hash := sha256.New()
+		_, err = io.Copy(hash, reader)
+		if err != nil {
+			return err
+		}
+		checksums[header.Name] = hex.EncodeToString(hash.Sum(nil))
+
+		return nil
+	})
+	if err != nil {
+		return nil, ErrCorruptedArchive(archivePath, err.Error())
+	}
+
+	if manifest == nil {
+		return nil, ErrCorruptedArchive(archivePath, "the manifest is missing")
+	}
+	if manifest.Version < 1 || manifest.Version > formatVersion {
+		return nil, fmt.Errorf("unsupported backup version %d, this meow version reads up to %d", manifest.Version, formatVersion)
+	}
+	if len(checksums) != len(manifest.Files) {
+		return nil, ErrCorruptedArchive(archivePath, fmt.Sprintf("the manifest lists %d files, the archive has %d", len(manifest.Files), len(checksums)))
+	}
+	for _, file := range manifest.Files {
+		if checksums[file.Path] != file.SHA256 {
+			return nil, ErrCorruptedArchive(archivePath, fmt.Sprintf("checksum mismatch for %q", file.Path))
+		}
+	}
+
+	return manifest, nil
+}
+
+// Restore verifies the archive and extracts its folders to the host paths.
+//
+// It fails if a folder is not empty, unless Force is set: the folder content is removed then,
+// keeping the folder itself since it may be a mount point.
+func Restore(archivePath string, config RestoreConfig) (*Manifest, error) {
+	manifest, err := Verify(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, folder := range Folders {
+		err = prepareFolder(config.FolderPaths[folder], config.Force)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = walkArchive(archivePath, func(header *tar.Header, reader io.Reader) error {
+		if header.Name == ManifestName {
+			return nil
+		}
+
+		// the entries are checked again, since the archive may have changed after the verification
+		folder, relativePath, err := checkEntry(header)
+		if err != nil {
+			return err
+		}
+
+		err = checkNoSymlinks(config.FolderPaths[folder], relativePath)
+		if err != nil {
+			return err
+		}
+
+		return extractEntry(header, reader, filepath.Join(config.FolderPaths[folder], filepath.FromSlash(relativePath)))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// walkArchive calls onEntry for each entry of the gzipped tar archive.
+func walkArchive(archivePath string, onEntry func(header *tar.Header, reader io.Reader) error) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	decompressor, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer decompressor.Close()
+
+	archive := tar.NewReader(decompressor)
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		err = onEntry(header, archive)
+		if err != nil {
+			return fmt.Errorf("%s: %w", header.Name, err)
+		}
+	}
+}
+
+// prepareFolder creates the folder, making sure it is empty.
+func prepareFolder(folder string, force bool) error {
+	err := os.MkdirAll(folder, 0o755)
+	if err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(folder)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	if !force {
+		return ErrFolderNotEmpty(folder)
+	}
+
+	for _, entry := range entries {
+		err = os.RemoveAll(filepath.Join(folder, entry.Name()))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkEntry returns the instance folder of the archive entry and its slash separated path relative to it,
+// rejecting the paths escaping the instance folders and the symbolic links pointing out of them.
+func checkEntry(header *tar.Header) (string, string, error) {
+	cleanName := path.Clean(strings.TrimSuffix(header.Name, "/"))
+	folder, relativePath, _ := strings.Cut(cleanName, "/")
+	if !slices.Contains(Folders, folder) || strings.HasPrefix(cleanName, "/") || relativePath == ".." || strings.HasPrefix(relativePath, "../") {
+		return "", "", fmt.Errorf("unexpected path in the backup archive")
+	}
+
+	if header.Typeflag == tar.TypeSymlink {
+		err := checkSymlink(relativePath, header.Linkname)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	return folder, relativePath, nil
+}
+
+// checkSymlink rejects the symbolic links whose target is absolute or resolves out of their instance folder.
+//
+// The ".." elements are allowed only at the start of the target: otherwise the target could go up
+// from another symbolic link of the folder, ending out of it even if its cleaned path is inside.
+func checkSymlink(relativePath string, linkname string) error {
+	if linkname == "" || path.IsAbs(linkname) || filepath.IsAbs(linkname) {
+		return ErrUnsafeSymlink(relativePath, fmt.Sprintf("the target %q is absolute", linkname))
+	}
+
+	ascending := true
+	for _, element := range strings.Split(linkname, "/") {
+		switch element {
+		case "..":
+			if !ascending {
+				return ErrUnsafeSymlink(relativePath, fmt.Sprintf("the target %q goes up after going down", linkname))
+			}
+		case ".", "":
+		default:
+			ascending = false
+		}
+	}
+
+	resolved := path.Join(path.Dir(relativePath), linkname)
+	if resolved == ".." || strings.HasPrefix(resolved, "../") {
+		return ErrUnsafeSymlink(relativePath, fmt.Sprintf("the target %q is out of the folder", linkname))
+	}
+
+	return nil
+}
+
+// checkNoSymlinks makes sure that neither the entry nor its parents, up to the instance folder,
+// are symbolic links, so that nothing is written out of the folder through them.
+func checkNoSymlinks(folderPath string, relativePath string) error {
+	if relativePath == "" {
+		return nil
+	}
+
+	current := folderPath
+	for _, element := range strings.Split(relativePath, "/") {
+		current = filepath.Join(current, element)
+		info, err := os.Lstat(current)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return ErrUnsafeSymlink(current, "refusing to write through it")
+		}
+	}
+
+	return nil
+}
+
+// extractEntry writes the archive entry to the target path.
+func extractEntry(header *tar.Header, reader io.Reader, target string) error {
+	mode := os.FileMode(header.Mode).Perm()
+
+	switch header.Typeflag {
+	case tar.TypeDir:
+		err := os.MkdirAll(target, 0o755)
+		if err != nil {
+			return err
+		}
+		return os.Chmod(target, mode)
+	case tar.TypeSymlink:
+		err := os.MkdirAll(filepath.Dir(target), 0o755)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(header.Linkname, target)
+	case tar.TypeReg:
+		err := os.MkdirAll(filepath.Dir(target), 0o755)
+		if err != nil {
+			return err
+		}
+
+		file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(file, reader)
+		if err != nil {
+			file.Close()
+			return err
+		}
+		err = file.Close()
+		if err != nil {
+			return err
+		}
+		return os.Chtimes(target, header.ModTime, header.ModTime)
+	default:
+		return nil
+	}
+}
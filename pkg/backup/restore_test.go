@@ -0,0 +1,321 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/saniales/meow-cli/internal/testutil"
+)
+
+// testEntry represents an entry of a hand-crafted backup archive.
+type testEntry struct {
+	name     string
+	typeflag byte
+	content  string
+	linkname string
+}
+
+// writeTestArchive writes a backup archive with the entries, followed by a manifest with their valid checksums,
+// as anyone able to craft the archive can do.
+func writeTestArchive(t *testing.T, archivePath string, entries []testEntry) {
+	t.Helper()
+
+	file, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	compressor := gzip.NewWriter(file)
+	archive := tar.NewWriter(compressor)
+	manifest := &Manifest{Version: formatVersion, CreatedAt: time.Now().UTC(), Files: []File{}}
+
+	writeEntry := func(header *tar.Header, content string) {
+		err := archive.WriteHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = archive.Write([]byte(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Typeflag: entry.typeflag, Linkname: entry.linkname, Mode: 0o644, ModTime: manifest.CreatedAt}
+		if entry.typeflag == tar.TypeDir {
+			header.Mode = 0o755
+		}
+		if entry.typeflag == tar.TypeReg {
+			header.Size = int64(len(entry.content))
+			checksum := sha256.Sum256([]byte(entry.content))
+			manifest.Files = append(manifest.Files, File{Path: entry.name, Size: header.Size, SHA256: hex.EncodeToString(checksum[:])})
+		}
+		writeEntry(header, entry.content)
+	}
+
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeEntry(&tar.Header{Name: ManifestName, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(content))}, string(content))
+
+	err = archive.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = compressor.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// instanceFolders returns the host paths of the instance folders in the root folder.
+func instanceFolders(root string) map[string]string {
+	folderPaths := make(map[string]string, len(Folders))
+	for _, folder := range Folders {
+		folderPaths[folder] = filepath.Join(root, folder)
+	}
+
+	return folderPaths
+}
+
+func TestCreateVerifyRestore(t *testing.T) {
+	sourceRoot := t.TempDir()
+	source := instanceFolders(sourceRoot)
+	files := map[string]string{
+		"plugins/weather/plugin.json": `{"name": "Weather"}`,
+		"plugins/weather/weather.py":  "print('sunny')",
+		"data/metadata.json":          "{}",
+		"data/local_vector_memory/x":  "vectors",
+	}
+	testutil.WriteFiles(t, sourceRoot, files)
+	err := os.Symlink("weather/weather.py", filepath.Join(source["plugins"], "current.py"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the static folder does not exist, and is archived as empty
+
+	archivePath := filepath.Join(t.TempDir(), "backup.tar.gz")
+	settings := map[string]any{"port": float64(1866), "container_name": "cat-production"}
+	created, err := Create(archivePath, Config{Instance: "default", Settings: settings, Image: "ghcr.io/cheshire-cat-ai/core:1.7.1", FolderPaths: source})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if len(created.Files) != len(files) {
+		t.Errorf("Create() archived %d files, want %d", len(created.Files), len(files))
+	}
+
+	verified, err := Verify(archivePath)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if verified.Instance != "default" || len(verified.Files) != len(files) {
+		t.Errorf("Verify() = %+v, want the manifest of the created backup", verified)
+	}
+	// the instance is recreated from the saved settings and image
+	if !reflect.DeepEqual(verified.Settings, settings) || verified.Image != "ghcr.io/cheshire-cat-ai/core:1.7.1" {
+		t.Errorf("Verify() settings = %v, image %q, want the ones of the created backup", verified.Settings, verified.Image)
+	}
+
+	destinationRoot := t.TempDir()
+	destination := instanceFolders(destinationRoot)
+	_, err = Restore(archivePath, RestoreConfig{FolderPaths: destination})
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	for name, content := range files {
+		restored, err := os.ReadFile(filepath.Join(destinationRoot, filepath.FromSlash(name)))
+		if err != nil || string(restored) != content {
+			t.Errorf("restored %s = %q (error %v), want %q", name, restored, err, content)
+		}
+	}
+	link, err := os.Readlink(filepath.Join(destination["plugins"], "current.py"))
+	if err != nil || link != "weather/weather.py" {
+		t.Errorf("restored symbolic link = %q (error %v), want weather/weather.py", link, err)
+	}
+	if info, err := os.Stat(destination["static"]); err != nil || !info.IsDir() {
+		t.Errorf("restored static folder error = %v, want an empty folder", err)
+	}
+
+	// restoring again over the restored files requires force
+	_, err = Restore(archivePath, RestoreConfig{FolderPaths: destination})
+	if err == nil {
+		t.Error("Restore() over non empty folders error = nil, want an error")
+	}
+	_, err = Restore(archivePath, RestoreConfig{FolderPaths: destination, Force: true})
+	if err != nil {
+		t.Errorf("Restore() with force error = %v", err)
+	}
+}
+
+func TestVerifyCorrupted(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteFiles(t, root, map[string]string{"data/metadata.json": "{}"})
+
+	archivePath := filepath.Join(t.TempDir(), "backup.tar.gz")
+	_, err := Create(archivePath, Config{FolderPaths: instanceFolders(root)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(archivePath, content[:len(content)/2], 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Verify(archivePath)
+	if err == nil {
+		t.Error("Verify() of a truncated archive error = nil, want an error")
+	}
+}
+
+func TestRestoreMaliciousArchive(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []testEntry
+		// verified is true when the archive can only be rejected while restoring, looking at the restored files
+		verified bool
+	}{
+		{
+			name: "absolute symbolic link written through",
+			entries: []testEntry{
+				{name: "plugins/x", typeflag: tar.TypeSymlink, linkname: "{outside}"},
+				{name: "plugins/x/pwned.txt", typeflag: tar.TypeReg, content: "pwned"},
+			},
+		},
+		{
+			name: "relative symbolic link out of the folder",
+			entries: []testEntry{
+				{name: "plugins/sub/x", typeflag: tar.TypeSymlink, linkname: "../../outside"},
+				{name: "plugins/sub/x/pwned.txt", typeflag: tar.TypeReg, content: "pwned"},
+			},
+		},
+		{
+			name: "symbolic link going up from another link",
+			entries: []testEntry{
+				{name: "plugins/self", typeflag: tar.TypeSymlink, linkname: "."},
+				{name: "plugins/x", typeflag: tar.TypeSymlink, linkname: "self/../outside"},
+				{name: "plugins/x/pwned.txt", typeflag: tar.TypeReg, content: "pwned"},
+			},
+		},
+		{
+			name: "file written through a link inside the folder",
+			entries: []testEntry{
+				{name: "plugins/x", typeflag: tar.TypeSymlink, linkname: "weather"},
+				{name: "plugins/x/pwned.txt", typeflag: tar.TypeReg, content: "pwned"},
+			},
+			verified: true,
+		},
+		{
+			name:    "path traversal",
+			entries: []testEntry{{name: "plugins/../../pwned.txt", typeflag: tar.TypeReg, content: "pwned"}},
+		},
+		{
+			name:    "unknown folder",
+			entries: []testEntry{{name: "etc/pwned.txt", typeflag: tar.TypeReg, content: "pwned"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			outside := t.TempDir()
+			entries := make([]testEntry, len(test.entries))
+			for index, entry := range test.entries {
+				if entry.linkname == "{outside}" {
+					entry.linkname = outside
+				}
+				entries[index] = entry
+			}
+
+			archivePath := filepath.Join(t.TempDir(), "backup.tar.gz")
+			writeTestArchive(t, archivePath, entries)
+
+			root := t.TempDir()
+			destination := instanceFolders(root)
+			// the relative links target a folder next to the instance folders
+			testutil.WriteFiles(t, root, map[string]string{"plugins/existing.txt": "keep", "outside/README": "outside"})
+			sibling := filepath.Join(root, "outside")
+			existing := filepath.Join(destination["plugins"], "existing.txt")
+
+			_, err := Verify(archivePath)
+			if (err == nil) != test.verified {
+				t.Errorf("Verify() error = %v, verified %v", err, test.verified)
+			}
+
+			_, err = Restore(archivePath, RestoreConfig{FolderPaths: destination, Force: true})
+			if err == nil {
+				t.Error("Restore() error = nil, want an error")
+			}
+
+			if _, err := os.Stat(filepath.Join(outside, "pwned.txt")); !os.IsNotExist(err) {
+				t.Errorf("a file was written out of the instance folders, error = %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(sibling, "pwned.txt")); !os.IsNotExist(err) {
+				t.Errorf("a file was written out of the instance folders, error = %v", err)
+			}
+			// an archive rejected by the verification does not clear the folders
+			if _, err := os.Stat(existing); err != nil && !test.verified {
+				t.Errorf("the folder was cleared before rejecting the archive, error = %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckSymlink(t *testing.T) {
+	tests := []struct {
+		relativePath string
+		linkname     string
+		wantErr      bool
+	}{
+		{relativePath: "current.py", linkname: "weather/weather.py"},
+		{relativePath: "weather/current.py", linkname: "./weather.py"},
+		{relativePath: "weather/lib/current.py", linkname: "../../other/main.py"},
+		{relativePath: "weather/self", linkname: ".."},
+		{relativePath: "current.py", linkname: "/etc/passwd", wantErr: true},
+		{relativePath: "current.py", linkname: "", wantErr: true},
+		{relativePath: "current.py", linkname: "..", wantErr: true},
+		{relativePath: "weather/current.py", linkname: "../../data/x", wantErr: true},
+		{relativePath: "current.py", linkname: "weather/../../x", wantErr: true},
+		{relativePath: "current.py", linkname: "weather/../main.py", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.relativePath+" -> "+test.linkname, func(t *testing.T) {
+			err := checkSymlink(test.relativePath, test.linkname)
+			if (err != nil) != test.wantErr {
+				t.Errorf("checkSymlink(%q, %q) error = %v, wantErr %v", test.relativePath, test.linkname, err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package ingest
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/saniales/meow-cli/pkg/providers/cat"
+)
+
+// LocationMetadataKey is the metadata key holding the location of the source in each of its memories,
+// identifying them even when several sources share the same name.
+const LocationMetadataKey = "meow_location"
+
+// Result represents the outcome of the ingestion of a source.
+type Result struct {
+	Source  Source        `json:"source"`
+	Info    string        `json:"info,omitempty"`
+	Error   error         `json:"-"`
+	Elapsed time.Duration `json:"elapsed"`
+}
+
+// Config represents the parameters of an ingestion.
+type Config struct {
+	// Workers is the number of concurrent uploads (at least 1).
+	Workers int
+	// Options are sent with each upload, along with the location of the source in the metadata (see LocationMetadataKey).
+	Options cat.UploadOptions
+	// OnStart is called by the worker when it starts uploading the source, and can wrap
+	// the reader of the file to track the upload progress (the reader is nil for web pages).
+	OnStart func(worker int, source Source, reader io.Reader) io.Reader
+	// OnDone is called by the worker when the upload of the source completes.
+	OnDone func(worker int, result Result)
+}
+
+// Ingest uploads the sources to the rabbit hole with a pool of workers, returning the results in the sources order.
+//
+// Once the context is done, the pending sources are not uploaded and their result holds the context error.
+func Ingest(ctx context.Context, client *cat.Client, sources []Source, config Config) []Result {
+	workers := max(config.Workers, 1)
+	results := make([]Result, len(sources))
+	jobs := make(chan int)
+
+	var waitGroup sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for index := range jobs {
+				results[index] = ingestSource(ctx, client, worker, sources[index], config)
+				if config.OnDone != nil {
+					config.OnDone(worker, results[index])
+				}
+			}
+		}()
+	}
+
+	for index := range sources {
+		jobs <- index
+	}
+	close(jobs)
+	waitGroup.Wait()
+
+	return results
+}
+
+func ingestSource(ctx context.Context, client *cat.Client, worker int, source Source, config Config) (result Result) {
+	result.Source = source
+	if ctx.Err() != nil {
+		result.Error = ctx.Err()
+		return result
+	}
+
+	start := time.Now()
+	defer func() {
+		result.Elapsed = time.Since(start)
+	}()
+
+	options := config.Options
+	options.Metadata = make(map[string]any, len(config.Options.Metadata)+1)
+	for key, value := range config.Options.Metadata {
+		options.Metadata[key] = value
+	}
+	options.Metadata[LocationMetadataKey] = source.Location
+
+	var uploadResult *cat.UploadResult
+	if source.IsURL() {
+		if config.OnStart != nil {
+			config.OnStart(worker, source, nil)
+		}
+		uploadResult, result.Error = client.UploadURL(ctx, source.Location, options)
+	} else {
+		file, err := os.Open(source.Location)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+		defer file.Close()
+
+		var reader io.Reader = file
+		if config.OnStart != nil {
+			reader = config.OnStart(worker, source, reader)
+		}
+		uploadResult, result.Error = client.UploadFile(ctx, source.Name, reader, options)
+	}
+	if uploadResult != nil {
+		result.Info = uploadResult.Info
+	}
+
+	return result
+}
@@ -0,0 +1,179 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package ingest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// manifestVersion is the version of the ingestion manifest format written by this CLI.
+const manifestVersion = 1
+
+// Manifest keeps the content hashes of the ingested sources, so that unchanged files are not ingested twice.
+type Manifest struct {
+	Version int `json:"version"`
+	// Sources are indexed by location.
+	Sources map[string]ManifestEntry `json:"sources"`
+}
+
+// ManifestEntry represents an ingested source.
+type ManifestEntry struct {
+	// Name is the source metadata of the memories of the source.
+	Name string `json:"name"`
+	// SHA256 is the checksum of the file content (empty for web pages).
+	SHA256     string    `json:"sha256,omitempty"`
+	IngestedAt time.Time `json:"ingested_at"`
+}
+
+// SyncPlan represents the changes of the sources since the last ingestion.
+type SyncPlan struct {
+	Added     []Source
+	Updated   []Source
+	Unchanged []Source
+	// Removed are the ingested files which do not exist anymore.
+	Removed []Source
+	// Failed are the files whose checksum cannot be computed, e.g. because they cannot be read.
+	Failed []Result
+}
+
+// ReadManifest reads the manifest at path, returning an empty one if it does not exist.
+func ReadManifest(path string) (*Manifest, error) {
+	manifest := &Manifest{Version: manifestVersion, Sources: map[string]ManifestEntry{}}
+
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(content, manifest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ingestion manifest %q: %w", path, err)
+	}
+	if manifest.Version != manifestVersion {
+		return nil, fmt.Errorf("unsupported ingestion manifest version %d, expected %d", manifest.Version, manifestVersion)
+	}
+	if manifest.Sources == nil {
+		manifest.Sources = map[string]ManifestEntry{}
+	}
+
+	return manifest, nil
+}
+
+// Write saves the manifest at path, replacing the previous one only once fully written.
+func (manifest *Manifest) Write(path string) error {
+	manifest.Version = manifestVersion
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	temporaryPath := path + ".tmp"
+	err = os.WriteFile(temporaryPath, content, 0o600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(temporaryPath, path)
+}
+
+// Plan computes the checksums of the sources and compares them with the ones of the last ingestion.
+//
+// Web pages are considered unchanged once ingested, since their content is fetched by the cat.
+// The files which cannot be read are reported as failed, without affecting the other ones.
+func (manifest *Manifest) Plan(sources []Source) *SyncPlan {
+	plan := new(SyncPlan)
+	for index := range sources {
+		source := &sources[index]
+		if !source.IsURL() {
+			checksum, err := fileChecksum(source.Location)
+			if err != nil {
+				plan.Failed = append(plan.Failed, Result{Source: *source, Error: err})
+				continue
+			}
+			source.SHA256 = checksum
+		}
+
+		entry, exists := manifest.Sources[source.Location]
+		switch {
+		case !exists:
+			plan.Added = append(plan.Added, *source)
+		case entry.SHA256 != source.SHA256 || entry.Name != source.Name:
+			plan.Updated = append(plan.Updated, *source)
+		default:
+			plan.Unchanged = append(plan.Unchanged, *source)
+		}
+	}
+
+	locations := make([]string, 0, len(manifest.Sources))
+	for location := range manifest.Sources {
+		locations = append(locations, location)
+	}
+	sort.Strings(locations)
+
+	for _, location := range locations {
+		if isURL(location) {
+			continue
+		}
+		_, err := os.Stat(location)
+		if os.IsNotExist(err) {
+			plan.Removed = append(plan.Removed, Source{Location: location, Name: manifest.Sources[location].Name})
+		}
+	}
+
+	return plan
+}
+
+// Record marks the source as ingested now.
+func (manifest *Manifest) Record(source Source) {
+	manifest.Sources[source.Location] = ManifestEntry{
+		Name:       source.Name,
+		SHA256:     source.SHA256,
+		IngestedAt: time.Now().UTC(),
+	}
+}
+
+// Forget removes the source from the manifest.
+func (manifest *Manifest) Forget(source Source) {
+	delete(manifest.Sources, source.Location)
+}
+
+// fileChecksum returns the hex encoded SHA256 of the file content.
+func fileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,129 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package ingest
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/saniales/meow-cli/internal/testutil"
+)
+
+func TestManifestPlan(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteFiles(t, root, map[string]string{
+		"added.md":     "new",
+		"updated.md":   "changed",
+		"renamed.md":   "same",
+		"unchanged.md": "same",
+		"folder/x.md":  "x",
+	})
+	location := func(name string) string {
+		return filepath.Join(root, name)
+	}
+	sameChecksum, err := fileChecksum(location("unchanged.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manifest := &Manifest{Version: manifestVersion, Sources: map[string]ManifestEntry{
+		location("updated.md"):     {Name: "updated.md", SHA256: sameChecksum},
+		location("renamed.md"):     {Name: "old/renamed.md", SHA256: sameChecksum},
+		location("unchanged.md"):   {Name: "unchanged.md", SHA256: sameChecksum},
+		location("deleted.md"):     {Name: "deleted.md", SHA256: sameChecksum},
+		"https://example.com/page": {Name: "https://example.com/page"},
+		"https://example.com/gone": {Name: "https://example.com/gone"},
+	}}
+
+	plan := manifest.Plan([]Source{
+		{Location: location("added.md"), Name: "added.md"},
+		{Location: location("updated.md"), Name: "updated.md"},
+		{Location: location("renamed.md"), Name: "renamed.md"},
+		{Location: location("unchanged.md"), Name: "unchanged.md"},
+		{Location: "https://example.com/page", Name: "https://example.com/page"},
+		{Location: "https://example.com/new", Name: "https://example.com/new"},
+		// a folder cannot be read as a file, failing the checksum
+		{Location: location("folder"), Name: "folder"},
+	})
+
+	names := func(sources []Source) []string {
+		var result []string
+		for _, source := range sources {
+			result = append(result, source.Name)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{name: "added", got: names(plan.Added), want: []string{"added.md", "https://example.com/new"}},
+		{name: "updated", got: names(plan.Updated), want: []string{"updated.md", "renamed.md"}},
+		{name: "unchanged", got: names(plan.Unchanged), want: []string{"unchanged.md", "https://example.com/page"}},
+		// web pages are never reported as removed, since their existence cannot be checked locally
+		{name: "removed", got: names(plan.Removed), want: []string{"deleted.md"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if !reflect.DeepEqual(test.got, test.want) {
+				t.Errorf("Plan() %s = %v, want %v", test.name, test.got, test.want)
+			}
+		})
+	}
+
+	if len(plan.Failed) != 1 || plan.Failed[0].Source.Name != "folder" || plan.Failed[0].Error == nil {
+		t.Errorf("Plan() failed = %+v, want only the folder with an error", plan.Failed)
+	}
+	if plan.Added[0].SHA256 == "" || plan.Added[1].SHA256 != "" {
+		t.Errorf("Plan() added checksums = %q, %q, want only the file one", plan.Added[0].SHA256, plan.Added[1].SHA256)
+	}
+}
+
+func TestManifestReadWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.json")
+
+	manifest, err := ReadManifest(path)
+	if err != nil {
+		t.Fatalf("ReadManifest() of a missing file error = %v", err)
+	}
+	if len(manifest.Sources) != 0 {
+		t.Errorf("ReadManifest() of a missing file = %+v, want an empty manifest", manifest)
+	}
+
+	source := Source{Location: "/docs/a.md", Name: "a.md", SHA256: "abc"}
+	manifest.Record(source)
+	manifest.Record(Source{Location: "/docs/b.md", Name: "b.md"})
+	manifest.Forget(Source{Location: "/docs/b.md"})
+	err = manifest.Write(path)
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	read, err := ReadManifest(path)
+	if err != nil {
+		t.Fatalf("ReadManifest() error = %v", err)
+	}
+	entry, exists := read.Sources[source.Location]
+	if len(read.Sources) != 1 || !exists || entry.Name != source.Name || entry.SHA256 != source.SHA256 || entry.IngestedAt.IsZero() {
+		t.Errorf("ReadManifest() = %+v, want only %+v", read.Sources, source)
+	}
+}
@@ -0,0 +1,212 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+// Package ingest collects the documents and feeds them to the cat rabbit hole.
+package ingest
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/saniales/meow-cli/pkg/plugin"
+)
+
+// Source represents a local file or a web page to ingest.
+type Source struct {
+	// Location is the absolute path of the file or the URL of the web page.
+	Location string `json:"location"`
+	// Name is sent to the cat as file name, so it is stored as source metadata of the memories.
+	Name string `json:"name"`
+	// Size is the size of the file in bytes (0 for web pages).
+	Size int64 `json:"size,omitempty"`
+	// SHA256 is the checksum of the file content, set when planning a sync.
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// IsURL checks whether the source is a web page.
+func (source Source) IsURL() bool {
+	return isURL(source.Location)
+}
+
+// CollectConfig represents the filters applied while walking the folders.
+type CollectConfig struct {
+	// Include are the .gitignore-style globs of the files to ingest (all the files if empty).
+	Include []string
+	// Exclude are the .gitignore-style globs of the files and folders to skip.
+	Exclude []string
+}
+
+// Collect expands the paths, folders, globs and URLs into the sources to ingest.
+//
+// It returns an error if any of the globs is invalid (e.g. a[z-a]).
+//
+// Folders are walked recursively, skipping hidden files and folders,
+// and their files are named after their path relative to the parent of the folder.
+// The include and exclude globs apply to the files found walking folders and globs,
+// files listed explicitly are always ingested.
+func Collect(args []string, config CollectConfig) ([]Source, error) {
+	include, err := plugin.NewIgnoreMatcher(config.Include)
+	if err != nil {
+		return nil, fmt.Errorf("include: %w", err)
+	}
+	exclude, err := plugin.NewIgnoreMatcher(config.Exclude)
+	if err != nil {
+		return nil, fmt.Errorf("exclude: %w", err)
+	}
+
+	collector := &collector{
+		include: include,
+		exclude: exclude,
+		filter:  len(config.Include) > 0,
+		seen:    map[string]bool{},
+	}
+
+	for _, arg := range args {
+		err := collector.collect(arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return collector.sources, nil
+}
+
+// collector accumulates the sources, skipping the duplicated ones.
+type collector struct {
+	include *plugin.IgnoreMatcher
+	exclude *plugin.IgnoreMatcher
+	filter  bool
+	seen    map[string]bool
+	sources []Source
+}
+
+func (collector *collector) collect(arg string) error {
+	if isURL(arg) {
+		collector.add(Source{Location: arg, Name: arg})
+		return nil
+	}
+
+	info, err := os.Stat(arg)
+	if os.IsNotExist(err) && strings.ContainsAny(arg, "*?[") {
+		return collector.collectGlob(arg)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		location, err := filepath.Abs(arg)
+		if err != nil {
+			return err
+		}
+		collector.add(Source{Location: location, Name: info.Name(), Size: info.Size()})
+		return nil
+	}
+
+	return collector.walk(arg, nil)
+}
+
+// collectGlob walks the folder before the first glob segment, keeping the files matching the glob.
+func (collector *collector) collectGlob(glob string) error {
+	segments := strings.Split(filepath.ToSlash(glob), "/")
+	base := 0
+	for base < len(segments)-1 && !strings.ContainsAny(segments[base], "*?[") {
+		base++
+	}
+
+	root := filepath.FromSlash(strings.Join(segments[:base], "/"))
+	if root == "" {
+		root = "."
+	}
+	if strings.HasPrefix(glob, "/") && root == "." {
+		root = "/"
+	}
+
+	matcher, err := plugin.NewIgnoreMatcher([]string{"/" + strings.Join(segments[base:], "/")})
+	if err != nil {
+		return err
+	}
+
+	return collector.walk(root, matcher)
+}
+
+// walk collects the files of the folder, keeping only the ones matching the glob (if not nil).
+func (collector *collector) walk(root string, glob *plugin.IgnoreMatcher) error {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+	rootName := filepath.Base(root)
+
+	return filepath.WalkDir(root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if filePath == root {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(root, filePath)
+		if err != nil {
+			return err
+		}
+		relativePath = filepath.ToSlash(relativePath)
+
+		skipped := strings.HasPrefix(entry.Name(), ".") || collector.exclude.Match(relativePath, entry.IsDir())
+		if entry.IsDir() {
+			if skipped {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if skipped || !entry.Type().IsRegular() {
+			return nil
+		}
+		if glob != nil && !glob.Match(relativePath, false) {
+			return nil
+		}
+		if collector.filter && !collector.include.Match(relativePath, false) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		collector.add(Source{Location: filePath, Name: path.Join(rootName, relativePath), Size: info.Size()})
+
+		return nil
+	})
+}
+
+func (collector *collector) add(source Source) {
+	if collector.seen[source.Location] {
+		return
+	}
+	collector.seen[source.Location] = true
+	collector.sources = append(collector.sources, source)
+}
+
+// isURL checks whether the argument is an HTTP(S) URL rather than a path.
+func isURL(arg string) bool {
+	parsedURL, err := url.Parse(arg)
+	return err == nil && (parsedURL.Scheme == "http" || parsedURL.Scheme == "https") && parsedURL.Host != ""
+}
@@ -0,0 +1,98 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package ingest
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/saniales/meow-cli/internal/testutil"
+)
+
+func TestCollect(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteFiles(t, root, map[string]string{
+		"docs/guide.md":       "guide",
+		"docs/api/index.md":   "api",
+		"docs/notes.txt":      "notes",
+		"docs/.hidden.md":     "hidden",
+		"docs/drafts/todo.md": "todo",
+	})
+
+	tests := []struct {
+		name string
+		// args are relative to the test folder
+		args    []string
+		config  CollectConfig
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "folder",
+			args: []string{"docs"},
+			want: []string{"docs/api/index.md", "docs/drafts/todo.md", "docs/guide.md", "docs/notes.txt"},
+		},
+		{
+			name:   "include and exclude",
+			args:   []string{"docs"},
+			config: CollectConfig{Include: []string{"*.md"}, Exclude: []string{"drafts/"}},
+			want:   []string{"docs/api/index.md", "docs/guide.md"},
+		},
+		{
+			name: "glob",
+			args: []string{"docs/*.md"},
+			want: []string{"docs/guide.md"},
+		},
+		{
+			name:   "explicit files ignore the filters, duplicates are skipped",
+			args:   []string{"docs/notes.txt", "docs/notes.txt", "https://example.com/page"},
+			config: CollectConfig{Include: []string{"*.md"}},
+			want:   []string{"notes.txt", "https://example.com/page"},
+		},
+		{name: "invalid include", args: []string{"docs"}, config: CollectConfig{Include: []string{"a[z-a]"}}, wantErr: true},
+		{name: "invalid exclude", args: []string{"docs"}, config: CollectConfig{Exclude: []string{"a[z-a]"}}, wantErr: true},
+		{name: "invalid glob", args: []string{"docs/a[z-a]"}, wantErr: true},
+		{name: "missing file", args: []string{"missing.md"}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var args []string
+			for _, arg := range test.args {
+				if !isURL(arg) {
+					arg = filepath.Join(root, arg)
+				}
+				args = append(args, arg)
+			}
+
+			sources, err := Collect(args, test.config)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Collect() error = %v, wantErr %v", err, test.wantErr)
+			}
+
+			var names []string
+			for _, source := range sources {
+				names = append(names, source.Name)
+			}
+			if !reflect.DeepEqual(names, test.want) {
+				t.Errorf("Collect() names = %v, want %v", names, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package memory
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/saniales/meow-cli/pkg/providers/cat"
+)
+
+// Encoding is the encoding of a memory file.
+type Encoding string
+
+const (
+	// EncodingJSON writes the whole snapshot as a single JSON document.
+	EncodingJSON Encoding = "json"
+	// EncodingNDJSON writes a header line followed by a line for each point and message,
+	// so that large snapshots can be processed line by line.
+	EncodingNDJSON Encoding = "ndjson"
+)
+
+// EncodingFromPath returns the encoding matching the extension of the file (.ndjson or .jsonl), JSON otherwise.
+func EncodingFromPath(path string) Encoding {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return EncodingNDJSON
+	default:
+		return EncodingJSON
+	}
+}
+
+// ParseEncoding validates the name of an encoding.
+func ParseEncoding(name string) (Encoding, error) {
+	switch encoding := Encoding(strings.ToLower(name)); encoding {
+	case EncodingJSON, EncodingNDJSON:
+		return encoding, nil
+	default:
+		return "", fmt.Errorf("unknown memory file encoding %q, expected %s or %s", name, EncodingJSON, EncodingNDJSON)
+	}
+}
+
+// ndjsonRecord represents a line of an NDJSON memory file after the header.
+type ndjsonRecord struct {
+	Collection string                   `json:"collection,omitempty"`
+	Point      *cat.MemoryPoint         `json:"point,omitempty"`
+	Message    *cat.ConversationMessage `json:"message,omitempty"`
+}
+
+// Write encodes the snapshot to the writer.
+func (snapshot *Snapshot) Write(writer io.Writer, encoding Encoding) error {
+	if encoding != EncodingNDJSON {
+		return json.NewEncoder(writer).Encode(snapshot)
+	}
+
+	buffer := bufio.NewWriter(writer)
+	encoder := json.NewEncoder(buffer)
+
+	header := *snapshot
+	header.Collections = nil
+	header.ConversationHistory = nil
+	err := encoder.Encode(header)
+	if err != nil {
+		return err
+	}
+
+	for _, collection := range snapshot.CollectionNames() {
+		for index := range snapshot.Collections[collection] {
+			err = encoder.Encode(ndjsonRecord{Collection: collection, Point: &snapshot.Collections[collection][index]})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for index := range snapshot.ConversationHistory {
+		err = encoder.Encode(ndjsonRecord{Message: &snapshot.ConversationHistory[index]})
+		if err != nil {
+			return err
+		}
+	}
+
+	return buffer.Flush()
+}
+
+// Read decodes a snapshot written with any encoding, validating its format and version.
+func Read(reader io.Reader) (*Snapshot, error) {
+	decoder := json.NewDecoder(bufio.NewReader(reader))
+
+	snapshot := new(Snapshot)
+	err := decoder.Decode(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.Format != Format {
+		return nil, fmt.Errorf("unknown format %q, expected %q", snapshot.Format, Format)
+	}
+	if snapshot.Version < 1 || snapshot.Version > FormatVersion {
+		return nil, fmt.Errorf("unsupported version %d, this meow version reads up to %d", snapshot.Version, FormatVersion)
+	}
+	if snapshot.Collections == nil {
+		snapshot.Collections = map[string][]cat.MemoryPoint{}
+	}
+
+	for line := 2; ; line++ {
+		var record ndjsonRecord
+		err = decoder.Decode(&record)
+		if errors.Is(err, io.EOF) {
+			return snapshot, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", line, err)
+		}
+
+		switch {
+		case record.Point != nil && record.Collection != "":
+			snapshot.Collections[record.Collection] = append(snapshot.Collections[record.Collection], *record.Point)
+		case record.Message != nil:
+			snapshot.ConversationHistory = append(snapshot.ConversationHistory, *record.Message)
+		default:
+			return nil, fmt.Errorf("record %d: expected a point with its collection or a message", line)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package memory
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saniales/meow-cli/pkg/providers/cat"
+)
+
+// newTestSnapshot returns a snapshot with points in two collections and a conversation history.
+func newTestSnapshot() *Snapshot {
+	return &Snapshot{
+		Format:    Format,
+		Version:   FormatVersion,
+		CreatedAt: time.Date(2024, time.June, 10, 10, 0, 0, 0, time.UTC),
+		Instance:  "default",
+		Embedder:  Embedder{Name: "EmbedderOpenAIConfig", Dimension: 3},
+		Collections: map[string][]cat.MemoryPoint{
+			"declarative": {
+				{ID: "1", Payload: cat.MemoryPayload{PageContent: "the cat is mad", Metadata: map[string]any{"source": "handbook.pdf"}}, Vector: []float64{0.1, 0.2, 0.3}},
+				{ID: "2", Payload: cat.MemoryPayload{PageContent: "so is the hatter", Metadata: map[string]any{"source": "handbook.pdf"}}, Vector: []float64{0.4, 0.5, 0.6}},
+			},
+			"episodic": {
+				{ID: "3", Payload: cat.MemoryPayload{PageContent: "hello", Metadata: map[string]any{"source": "user", "when": 1718013600.5}}, Vector: []float64{0.7, 0.8, 0.9}},
+			},
+		},
+		ConversationHistory: []cat.ConversationMessage{
+			{Who: "Human", Message: "hello", When: 1718013600.5},
+			{Who: "AI", Message: "We're all mad here", When: 1718013601},
+		},
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	empty := &Snapshot{Format: Format, Version: FormatVersion, CreatedAt: time.Date(2024, time.June, 10, 10, 0, 0, 0, time.UTC), Collections: map[string][]cat.MemoryPoint{}}
+
+	tests := []struct {
+		name     string
+		snapshot *Snapshot
+		encoding Encoding
+	}{
+		{name: "json", snapshot: newTestSnapshot(), encoding: EncodingJSON},
+		{name: "ndjson", snapshot: newTestSnapshot(), encoding: EncodingNDJSON},
+		{name: "empty json", snapshot: empty, encoding: EncodingJSON},
+		{name: "empty ndjson", snapshot: empty, encoding: EncodingNDJSON},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			err := test.snapshot.Write(&buffer, test.encoding)
+			if err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+
+			lines := strings.Count(buffer.String(), "\n")
+			wantLines := 1
+			if test.encoding == EncodingNDJSON {
+				wantLines += test.snapshot.PointsCount() + len(test.snapshot.ConversationHistory)
+			}
+			if lines != wantLines {
+				t.Errorf("Write() wrote %d lines, want %d", lines, wantLines)
+			}
+
+			got, err := Read(&buffer)
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, test.snapshot) {
+				t.Errorf("Read() = %+v, want %+v", got, test.snapshot)
+			}
+		})
+	}
+}
+
+func TestSnapshotFileRoundTrip(t *testing.T) {
+	snapshot := newTestSnapshot()
+
+	for _, name := range []string{"memories.json", "memories.ndjson", "memories.jsonl"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			err := snapshot.WriteFile(path, EncodingFromPath(path))
+			if err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			got, err := ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, snapshot) {
+				t.Errorf("ReadFile() = %+v, want %+v", got, snapshot)
+			}
+		})
+	}
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "not JSON", content: "points", wantErr: "invalid character"},
+		{name: "empty", content: "", wantErr: "EOF"},
+		{name: "missing format", content: `{"version": 1}`, wantErr: `unknown format ""`},
+		{name: "unknown format", content: `{"format": "qdrant", "version": 1}`, wantErr: `unknown format "qdrant"`},
+		{name: "version zero", content: `{"format": "meow-memory", "version": 0}`, wantErr: "unsupported version 0"},
+		{name: "future version", content: `{"format": "meow-memory", "version": 99}`, wantErr: "unsupported version 99"},
+		{
+			name:    "point without collection",
+			content: `{"format": "meow-memory", "version": 1}` + "\n" + `{"point": {"id": "1"}}`,
+			wantErr: "record 2: expected a point with its collection or a message",
+		},
+		{
+			name:    "invalid record",
+			content: `{"format": "meow-memory", "version": 1}` + "\n" + `{"collection": "declarative", "point": {"id": "1"}}` + "\n" + `{"point": `,
+			wantErr: "record 3:",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(test.content))
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("Read() error = %v, want it to contain %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseEncoding(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Encoding
+		wantErr bool
+	}{
+		{name: "json", want: EncodingJSON},
+		{name: "NDJSON", want: EncodingNDJSON},
+		{name: "jsonl", wantErr: true},
+		{name: "", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseEncoding(test.name)
+			if (err != nil) != test.wantErr || got != test.want {
+				t.Errorf("ParseEncoding(%q) = %q, %v, want %q, wantErr %v", test.name, got, err, test.want, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestEncodingFromPath(t *testing.T) {
+	tests := map[string]Encoding{
+		"memories.json":   EncodingJSON,
+		"memories.ndjson": EncodingNDJSON,
+		"memories.JSONL":  EncodingNDJSON,
+		"memories":        EncodingJSON,
+		"-":               EncodingJSON,
+	}
+
+	for path, want := range tests {
+		if got := EncodingFromPath(path); got != want {
+			t.Errorf("EncodingFromPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
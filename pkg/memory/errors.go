@@ -0,0 +1,29 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package memory
+
+import (
+	"fmt"
+)
+
+// ErrEmbedderMismatch is returned when the vectors of a snapshot were produced by a different embedder than the cat one.
+func ErrEmbedderMismatch(snapshot Embedder, target Embedder) error {
+	return fmt.Errorf(
+		"the memories were embedded with %s (dimension %d) but the cat uses %s (dimension %d), their vectors are not compatible",
+		snapshot.Name, snapshot.Dimension, target.Name, target.Dimension,
+	)
+}
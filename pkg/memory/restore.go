@@ -0,0 +1,157 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package memory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/saniales/meow-cli/pkg/providers/cat"
+)
+
+// DeclarativeCollection is the collection restored through the rabbit hole, keeping the ids and vectors of the points.
+const DeclarativeCollection = "declarative"
+
+// ProceduralCollection is rebuilt by the cat from the tools and forms of the plugins, so it is never restored.
+const ProceduralCollection = "procedural"
+
+// RestoreResult represents the points restored in a collection.
+type RestoreResult struct {
+	Collection string `json:"collection"`
+	Points     int    `json:"points"`
+	// Reembedded is true when the points were created again from their content,
+	// since the cat API accepts the vectors only for the declarative memory.
+	Reembedded bool `json:"reembedded"`
+}
+
+// CurrentEmbedder returns the embedder of the cat, with the dimension of its vectors.
+func CurrentEmbedder(ctx context.Context, client *cat.Client) (Embedder, error) {
+	embedders, err := client.GetEmbedderSettings(ctx)
+	if err != nil {
+		return Embedder{}, err
+	}
+
+	// the recall response includes the embedded query, the cheapest way to know the vectors dimension
+	recall, err := client.Recall(ctx, "meow", 1)
+	if err != nil {
+		return Embedder{}, err
+	}
+
+	return Embedder{Name: embedders.SelectedConfiguration, Dimension: len(recall.Query.Vector)}, nil
+}
+
+// CheckEmbedder verifies that the vectors of the snapshot are compatible with the target embedder,
+// skipping the checks on the values unknown on either side.
+func (snapshot *Snapshot) CheckEmbedder(target Embedder) error {
+	if snapshot.Embedder.Name != "" && target.Name != "" && snapshot.Embedder.Name != target.Name {
+		return ErrEmbedderMismatch(snapshot.Embedder, target)
+	}
+	if snapshot.Embedder.Dimension != 0 && target.Dimension != 0 && snapshot.Embedder.Dimension != target.Dimension {
+		return ErrEmbedderMismatch(snapshot.Embedder, target)
+	}
+
+	return nil
+}
+
+// Restore uploads the points of the collections of the snapshot to the cat.
+func Restore(ctx context.Context, client *cat.Client, snapshot *Snapshot, collections []string) ([]RestoreResult, error) {
+	results := make([]RestoreResult, 0, len(collections))
+	for _, collection := range collections {
+		points, exists := snapshot.Collections[collection]
+		if !exists {
+			return results, fmt.Errorf("collection %q is not in the memory file", collection)
+		}
+		if collection == ProceduralCollection {
+			return results, fmt.Errorf("collection %q is rebuilt by the cat from its plugins and cannot be restored", collection)
+		}
+		if len(points) == 0 {
+			results = append(results, RestoreResult{Collection: collection})
+			continue
+		}
+
+		var err error
+		if collection == DeclarativeCollection {
+			err = uploadDeclarative(ctx, client, snapshot.Embedder, points)
+		} else {
+			err = createPoints(ctx, client, collection, points)
+		}
+		if err != nil {
+			return results, fmt.Errorf("cannot restore collection %q: %w", collection, err)
+		}
+
+		results = append(results, RestoreResult{
+			Collection: collection,
+			Points:     len(points),
+			Reembedded: collection != DeclarativeCollection,
+		})
+	}
+
+	return results, nil
+}
+
+// uploadDeclarative sends the points to the rabbit hole, in the format of the memory files exported by the cat admin.
+func uploadDeclarative(ctx context.Context, client *cat.Client, embedder Embedder, points []cat.MemoryPoint) error {
+	type catMemory struct {
+		ID          string         `json:"id"`
+		PageContent string         `json:"page_content"`
+		Metadata    map[string]any `json:"metadata"`
+		Vector      []float64      `json:"vector"`
+	}
+
+	memories := make([]catMemory, 0, len(points))
+	for _, point := range points {
+		if len(point.Vector) == 0 {
+			return fmt.Errorf("point %q has no vector", point.ID)
+		}
+		memories = append(memories, catMemory{
+			ID:          point.ID,
+			PageContent: point.Payload.PageContent,
+			Metadata:    point.Payload.Metadata,
+			Vector:      point.Vector,
+		})
+	}
+
+	content, err := json.Marshal(map[string]any{
+		"embedder": map[string]any{
+			"name": embedder.Name,
+			"size": embedder.Dimension,
+		},
+		"collections": map[string]any{
+			DeclarativeCollection: memories,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = client.UploadMemory(ctx, "memories.json", bytes.NewReader(content))
+	return err
+}
+
+// createPoints creates the points again from their content and metadata.
+func createPoints(ctx context.Context, client *cat.Client, collection string, points []cat.MemoryPoint) error {
+	for _, point := range points {
+		_, err := client.CreatePoint(ctx, collection, point.Payload.PageContent, point.Payload.Metadata)
+		if err != nil {
+			return fmt.Errorf("point %q: %w", point.ID, err)
+		}
+	}
+
+	return nil
+}
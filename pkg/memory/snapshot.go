@@ -0,0 +1,177 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package memory contains the portable format of the cat memories, used by snapshots and exports.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/saniales/meow-cli/pkg/providers/cat"
+)
+
+// Format identifies the files written by meow with the cat memories.
+const Format = "meow-memory"
+
+// FormatVersion is the version of the memory files written by this CLI.
+const FormatVersion = 1
+
+// pointsPageSize is the number of points fetched with each request.
+const pointsPageSize = 1000
+
+// Embedder identifies the embedder which produced the vectors, since vectors of different embedders are not compatible.
+type Embedder struct {
+	Name      string `json:"name"`
+	Dimension int    `json:"dimension"`
+}
+
+// Snapshot represents the memories of a cat, with their vectors.
+type Snapshot struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Instance  string    `json:"instance,omitempty"`
+	Embedder  Embedder  `json:"embedder"`
+	// Collections are the points of each dumped collection.
+	Collections map[string][]cat.MemoryPoint `json:"collections,omitempty"`
+	// ConversationHistory is the conversation history of the user of the client, if dumped.
+	ConversationHistory []cat.ConversationMessage `json:"conversation_history,omitempty"`
+}
+
+// DumpConfig represents the memories to dump.
+type DumpConfig struct {
+	// Instance is the name of the dumped instance, for reference.
+	Instance string
+	// Collections are the names of the collections to dump.
+	Collections []string
+	// Conversation dumps the conversation history too.
+	Conversation bool
+}
+
+// Dump reads the selected memories from the cat.
+func Dump(ctx context.Context, client *cat.Client, config DumpConfig) (*Snapshot, error) {
+	snapshot := &Snapshot{
+		Format:      Format,
+		Version:     FormatVersion,
+		CreatedAt:   time.Now().UTC(),
+		Instance:    config.Instance,
+		Collections: make(map[string][]cat.MemoryPoint, len(config.Collections)),
+	}
+
+	embedders, err := client.GetEmbedderSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Embedder.Name = embedders.SelectedConfiguration
+
+	for _, collection := range config.Collections {
+		points, err := dumpCollection(ctx, client, collection)
+		if err != nil {
+			return nil, fmt.Errorf("cannot dump collection %q: %w", collection, err)
+		}
+		snapshot.Collections[collection] = points
+
+		if snapshot.Embedder.Dimension == 0 && len(points) > 0 {
+			snapshot.Embedder.Dimension = len(points[0].Vector)
+		}
+	}
+
+	if config.Conversation {
+		snapshot.ConversationHistory, err = client.GetConversationHistory(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("cannot dump the conversation history: %w", err)
+		}
+	}
+
+	return snapshot, nil
+}
+
+// dumpCollection returns all the points of the collection, fetching all the pages.
+func dumpCollection(ctx context.Context, client *cat.Client, collection string) ([]cat.MemoryPoint, error) {
+	points := make([]cat.MemoryPoint, 0)
+
+	var offset cat.Offset
+	for {
+		page, err := client.GetPoints(ctx, collection, pointsPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		points = append(points, page.Points...)
+		if page.NextOffset == "" {
+			return points, nil
+		}
+		offset = page.NextOffset
+	}
+}
+
+// PointsCount returns the total number of points of the snapshot.
+func (snapshot *Snapshot) PointsCount() int {
+	count := 0
+	for _, points := range snapshot.Collections {
+		count += len(points)
+	}
+
+	return count
+}
+
+// CollectionNames returns the sorted names of the collections of the snapshot.
+func (snapshot *Snapshot) CollectionNames() []string {
+	names := make([]string, 0, len(snapshot.Collections))
+	for name := range snapshot.Collections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// WriteFile saves the snapshot with the encoding, readable only by the user since memories can be sensitive.
+func (snapshot *Snapshot) WriteFile(path string, encoding Encoding) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+
+	err = snapshot.Write(file, encoding)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// ReadFile reads a snapshot written with any encoding.
+func ReadFile(path string) (*Snapshot, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	snapshot, err := Read(file)
+	if err != nil {
+		return nil, fmt.Errorf("invalid memory file %q: %w", path, err)
+	}
+
+	return snapshot, nil
+}
@@ -0,0 +1,158 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package plugin contains the tools to develop and package cat plugins.
+package plugin
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// skippedFolders are never included in the plugin archives.
+var skippedFolders = map[string]bool{
+	".git":        true,
+	"__pycache__": true,
+	".venv":       true,
+}
+
+// zipTimestamp is the modification time of all the archive entries, to build reproducible archives.
+var zipTimestamp = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// ZipConfig represents the optional parameters used to build the plugin archive.
+type ZipConfig struct {
+	// Overrides replaces the content of the files, by slash separated path relative to the plugin folder.
+	Overrides map[string][]byte
+	// Exclude are the absolute paths of additional files not included in the archive (e.g. the archive itself).
+	Exclude []string
+}
+
+// WriteZip writes to output a zip archive with the content of the plugin folder,
+// placed inside a top level folder named rootName.
+//
+// The archive is reproducible: entries are sorted and have fixed timestamps and permissions.
+// The files matching the patterns of the plugin ignore files (.gitignore, .meowignore) are excluded.
+func WriteZip(folder string, rootName string, output io.Writer, config ZipConfig) error {
+	files, err := listArchiveFiles(folder, config.Exclude)
+	if err != nil {
+		return err
+	}
+
+	archive := zip.NewWriter(output)
+	for _, file := range files {
+		var content io.Reader
+		if override, exists := config.Overrides[file]; exists {
+			content = bytes.NewReader(override)
+		}
+
+		err = addZipFile(archive, filepath.Join(folder, filepath.FromSlash(file)), path.Join(rootName, file), content)
+		if err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// listArchiveFiles returns the sorted slash separated paths of the files to include in the archive.
+func listArchiveFiles(folder string, exclude []string) ([]string, error) {
+	ignoreMatcher, err := LoadIgnoreMatcher(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := make(map[string]bool, len(exclude))
+	for _, excludedPath := range exclude {
+		excluded[filepath.Clean(excludedPath)] = true
+	}
+
+	var files []string
+	err = filepath.WalkDir(folder, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if filePath == folder {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(folder, filePath)
+		if err != nil {
+			return err
+		}
+		relativePath = filepath.ToSlash(relativePath)
+
+		if entry.IsDir() {
+			if skippedFolders[entry.Name()] || ignoreMatcher.Match(relativePath, true) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if entry.Type().IsRegular() && !excluded[filepath.Clean(filePath)] && !ignoreMatcher.Match(relativePath, false) {
+			files = append(files, relativePath)
+		}
+
+		return nil
+	})
+	sort.Strings(files)
+
+	return files, err
+}
+
+// addZipFile adds the file to the archive, reading it from content if not nil.
+func addZipFile(archive *zip.Writer, filePath string, name string, content io.Reader) error {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+
+	if content == nil {
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		content = file
+	}
+
+	header := &zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: zipTimestamp,
+	}
+	mode := fs.FileMode(0o644)
+	if info.Mode()&0o111 != 0 {
+		mode = 0o755
+	}
+	header.SetMode(mode)
+
+	writer, err := archive.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(writer, content)
+	return err
+}
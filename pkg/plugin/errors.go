@@ -0,0 +1,35 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package plugin
+
+import (
+	"fmt"
+)
+
+var (
+	ErrEmptyPluginName = fmt.Errorf("the plugin name cannot be empty")
+)
+
+// ErrFolderNotEmpty is returned when the plugin would be generated in a folder which already has content.
+func ErrFolderNotEmpty(folder string) error {
+	return fmt.Errorf("the folder %q already exists and is not empty", folder)
+}
+
+// ErrInvalidPattern is returned when a .gitignore-style pattern cannot be compiled.
+func ErrInvalidPattern(pattern string, err error) error {
+	return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+}
@@ -0,0 +1,170 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package plugin
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// IgnoreFiles are the files, in the plugin root folder, with the patterns of the files excluded from the archives.
+var IgnoreFiles = []string{".gitignore", ".meowignore"}
+
+// ignorePattern represents a single .gitignore pattern.
+type ignorePattern struct {
+	pattern  *regexp.Regexp
+	negated  bool
+	onlyDirs bool
+}
+
+// IgnoreMatcher matches the paths against .gitignore-style patterns,
+// where the last matching pattern wins and ! negates a pattern.
+type IgnoreMatcher struct {
+	patterns []ignorePattern
+}
+
+// NewIgnoreMatcher parses the .gitignore-style patterns, skipping blank lines and comments.
+//
+// It returns ErrInvalidPattern for the patterns which cannot be compiled (e.g. [z-a]).
+func NewIgnoreMatcher(lines []string) (*IgnoreMatcher, error) {
+	matcher := new(IgnoreMatcher)
+	for _, rawLine := range lines {
+		line := strings.TrimRight(rawLine, " \r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var pattern ignorePattern
+		if negatedLine, found := strings.CutPrefix(line, "!"); found {
+			pattern.negated = true
+			line = negatedLine
+		}
+		line = strings.TrimPrefix(line, `\`)
+
+		if dirLine, found := strings.CutSuffix(line, "/"); found {
+			pattern.onlyDirs = true
+			line = dirLine
+		}
+
+		// patterns with a slash (except a trailing one) are relative to the root folder,
+		// the other ones match at any depth
+		anchored := strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		if line == "" {
+			continue
+		}
+
+		expression := globToRegexp(line)
+		if !anchored {
+			expression = "(?:.*/)?" + expression
+		}
+		compiled, err := regexp.Compile("^" + expression + "$")
+		if err != nil {
+			return nil, ErrInvalidPattern(strings.TrimSpace(rawLine), err)
+		}
+		pattern.pattern = compiled
+
+		matcher.patterns = append(matcher.patterns, pattern)
+	}
+
+	return matcher, nil
+}
+
+// LoadIgnoreMatcher reads the ignore files of the plugin folder, if any.
+func LoadIgnoreMatcher(folder string) (*IgnoreMatcher, error) {
+	var lines []string
+	for _, name := range IgnoreFiles {
+		file, err := os.Open(filepath.Join(folder, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		file.Close()
+		if scanner.Err() != nil {
+			return nil, scanner.Err()
+		}
+	}
+
+	return NewIgnoreMatcher(lines)
+}
+
+// Match checks whether the slash separated path, relative to the root folder, is ignored.
+func (matcher *IgnoreMatcher) Match(relativePath string, isDir bool) bool {
+	ignored := false
+	for _, pattern := range matcher.patterns {
+		if pattern.onlyDirs && !isDir {
+			continue
+		}
+		if pattern.pattern.MatchString(relativePath) {
+			ignored = !pattern.negated
+		}
+	}
+
+	return ignored
+}
+
+// globToRegexp converts a .gitignore glob to a regular expression.
+func globToRegexp(glob string) string {
+	var expression strings.Builder
+	for index := 0; index < len(glob); index++ {
+		char := glob[index]
+		switch {
+		case strings.HasPrefix(glob[index:], "**/"):
+			expression.WriteString("(?:.*/)?")
+			index += 2
+		case strings.HasPrefix(glob[index:], "/**") && index+3 == len(glob):
+			expression.WriteString("/.*")
+			index += 2
+		case strings.HasPrefix(glob[index:], "**"):
+			expression.WriteString(".*")
+			index++
+		case char == '*':
+			expression.WriteString("[^/]*")
+		case char == '?':
+			expression.WriteString("[^/]")
+		case char == '[':
+			end := strings.IndexByte(glob[index:], ']')
+			if end < 0 {
+				expression.WriteString(`\[`)
+				continue
+			}
+			class := glob[index+1 : index+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expression.WriteString("[" + class + "]")
+			index += end
+		case char == '\\' && index+1 < len(glob):
+			index++
+			expression.WriteString(regexp.QuoteMeta(string(glob[index])))
+		default:
+			expression.WriteString(regexp.QuoteMeta(string(char)))
+		}
+	}
+
+	return expression.String()
+}
@@ -0,0 +1,292 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Severity represents how serious a lint issue is.
+type Severity string
+
+// Severities of the lint issues.
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Issue represents a problem found by the plugin linter.
+type Issue struct {
+	Severity Severity `json:"severity"`
+	// Rule is the identifier of the check which found the issue.
+	Rule    string `json:"rule"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+func (issue Issue) String() string {
+	position := issue.File
+	if issue.Line > 0 {
+		position += fmt.Sprintf(":%d", issue.Line)
+		if issue.Column > 0 {
+			position += fmt.Sprintf(":%d", issue.Column)
+		}
+	}
+	if position == "" {
+		position = "."
+	}
+
+	return fmt.Sprintf("%s: %s: %s (%s)", position, issue.Severity, issue.Message, issue.Rule)
+}
+
+// LintConfig represents the parameters of the plugin linter.
+type LintConfig struct {
+	// CoreRequirements are the packages pinned by the cat core, by normalized name
+	// (default is DefaultCoreRequirements).
+	CoreRequirements map[string]string
+}
+
+// semverPattern matches the semantic versions used in plugin.json.
+var semverPattern = regexp.MustCompile(`^\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+
+// Lint checks the plugin folder, returning the issues sorted by file and position.
+func Lint(folder string, config LintConfig) ([]Issue, error) {
+	if config.CoreRequirements == nil {
+		config.CoreRequirements = DefaultCoreRequirements
+	}
+
+	info, err := os.Stat(folder)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%q is not a folder", folder)
+	}
+
+	var issues []Issue
+	issues = append(issues, lintManifest(folder)...)
+
+	pythonFiles, err := listPythonFiles(folder)
+	if err != nil {
+		return nil, err
+	}
+	if len(pythonFiles) == 0 {
+		issues = append(issues, Issue{Severity: SeverityError, Rule: "structure", Message: "the plugin has no Python modules"})
+	}
+	if _, err := os.Stat(filepath.Join(folder, "README.md")); err != nil {
+		issues = append(issues, Issue{Severity: SeverityInfo, Rule: "structure", File: "README.md", Message: "the plugin has no README.md"})
+	}
+
+	hooks := make(map[string][]Issue)
+	for _, file := range pythonFiles {
+		source, err := os.ReadFile(filepath.Join(folder, file))
+		if err != nil {
+			return nil, err
+		}
+
+		issues = append(issues, checkPythonSyntax(file, source)...)
+		for _, hook := range findHooks(file, source) {
+			hooks[hook.Message] = append(hooks[hook.Message], hook)
+		}
+	}
+	issues = append(issues, duplicatedHooks(hooks)...)
+
+	requirementsIssues, err := lintRequirements(folder, config.CoreRequirements)
+	if err != nil {
+		return nil, err
+	}
+	issues = append(issues, requirementsIssues...)
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].File != issues[j].File {
+			return issues[i].File < issues[j].File
+		}
+		return issues[i].Line < issues[j].Line
+	})
+
+	return issues, nil
+}
+
+// lintManifest checks that plugin.json is valid JSON with the required fields.
+func lintManifest(folder string) []Issue {
+	const file = "plugin.json"
+
+	content, err := os.ReadFile(filepath.Join(folder, file))
+	if err != nil {
+		return []Issue{{Severity: SeverityError, Rule: "manifest", File: file, Message: "plugin.json is missing"}}
+	}
+
+	var manifest map[string]any
+	err = json.Unmarshal(content, &manifest)
+	if err != nil {
+		issue := Issue{Severity: SeverityError, Rule: "manifest", File: file, Message: fmt.Sprintf("invalid JSON: %s", err)}
+
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			issue.Line, issue.Column = offsetPosition(content, int(syntaxErr.Offset))
+		}
+		return []Issue{issue}
+	}
+
+	var issues []Issue
+	requiredFields := []struct {
+		name     string
+		severity Severity
+	}{
+		{"name", SeverityError},
+		{"version", SeverityWarning},
+		{"description", SeverityWarning},
+		{"author_name", SeverityInfo},
+	}
+	for _, field := range requiredFields {
+		value, exists := manifest[field.name]
+		text, isString := value.(string)
+		if !exists || !isString || strings.TrimSpace(text) == "" {
+			issues = append(issues, Issue{
+				Severity: field.severity,
+				Rule:     "manifest",
+				File:     file,
+				Line:     keyLine(content, field.name),
+				Message:  fmt.Sprintf("the %q field is missing or empty", field.name),
+			})
+		}
+	}
+
+	if version, ok := manifest["version"].(string); ok && version != "" && !semverPattern.MatchString(version) {
+		issues = append(issues, Issue{
+			Severity: SeverityWarning,
+			Rule:     "manifest-version",
+			File:     file,
+			Line:     keyLine(content, "version"),
+			Message:  fmt.Sprintf("version %q is not a semantic version (e.g. 1.0.0)", version),
+		})
+	}
+
+	return issues
+}
+
+// keyLine returns the line where the JSON key is defined, or 0 if not found.
+func keyLine(content []byte, key string) int {
+	index := bytes.Index(content, []byte(`"`+key+`"`))
+	if index < 0 {
+		return 0
+	}
+
+	line, _ := offsetPosition(content, index)
+	return line
+}
+
+// offsetPosition converts the byte offset in the content to a 1-based line and column.
+func offsetPosition(content []byte, offset int) (int, int) {
+	offset = min(offset, len(content))
+	before := content[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := offset - bytes.LastIndexByte(before, '\n')
+
+	return line, column
+}
+
+// listPythonFiles returns the sorted relative paths of the Python files in the plugin.
+func listPythonFiles(folder string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(folder, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() && filePath != folder && skippedFolders[entry.Name()] {
+			return filepath.SkipDir
+		}
+
+		if entry.Type().IsRegular() && strings.HasSuffix(entry.Name(), ".py") {
+			relativePath, err := filepath.Rel(folder, filePath)
+			if err != nil {
+				return err
+			}
+			files = append(files, filepath.ToSlash(relativePath))
+		}
+
+		return nil
+	})
+	sort.Strings(files)
+
+	return files, err
+}
+
+var (
+	hookDecoratorPattern = regexp.MustCompile(`^@hook\b`)
+	functionPattern      = regexp.MustCompile(`^(?:async\s+)?def\s+([A-Za-z_]\w*)`)
+)
+
+// findHooks returns the top level functions decorated with @hook, with the hook name as message.
+func findHooks(file string, source []byte) []Issue {
+	var hooks []Issue
+	decorated := false
+	for index, line := range strings.Split(string(source), "\n") {
+		switch {
+		case hookDecoratorPattern.MatchString(line):
+			decorated = true
+		case strings.HasPrefix(line, "@"):
+			// other decorators stacked with @hook
+		case functionPattern.MatchString(line):
+			if decorated {
+				name := functionPattern.FindStringSubmatch(line)[1]
+				hooks = append(hooks, Issue{File: file, Line: index + 1, Message: name})
+			}
+			decorated = false
+		case strings.TrimSpace(line) != "" && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") && !strings.HasPrefix(line, "#"):
+			decorated = false
+		}
+	}
+
+	return hooks
+}
+
+// duplicatedHooks reports the hooks implemented more than once in the plugin.
+func duplicatedHooks(hooks map[string][]Issue) []Issue {
+	var issues []Issue
+	for name, definitions := range hooks {
+		if len(definitions) < 2 {
+			continue
+		}
+
+		first := definitions[0]
+		for _, definition := range definitions[1:] {
+			issues = append(issues, Issue{
+				Severity: SeverityWarning,
+				Rule:     "duplicated-hook",
+				File:     definition.File,
+				Line:     definition.Line,
+				Message:  fmt.Sprintf("hook %q is already defined at %s:%d", name, first.File, first.Line),
+			})
+		}
+	}
+
+	return issues
+}
@@ -0,0 +1,254 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package plugin
+
+import (
+	"fmt"
+	"strings"
+)
+
+// blockKeywords start the Python statements which require a colon and an indented block.
+var blockKeywords = map[string]bool{
+	"def": true, "class": true, "if": true, "elif": true, "else": true, "for": true, "while": true,
+	"try": true, "except": true, "finally": true, "with": true, "async": true,
+}
+
+// closingBrackets maps the closing brackets to the opening ones.
+var closingBrackets = map[rune]rune{')': '(', ']': '[', '}': '{'}
+
+type openBracket struct {
+	char   rune
+	line   int
+	column int
+}
+
+// pythonChecker finds the syntax errors detectable at tokenizer level,
+// without needing a Python interpreter: unterminated strings, unbalanced brackets,
+// inconsistent indentation and block statements without colon.
+type pythonChecker struct {
+	file   string
+	issues []Issue
+
+	brackets []openBracket
+	// stringDelimiter is the quote of the string being scanned across lines (triple quoted), if any.
+	stringDelimiter string
+	stringLine      int
+	stringColumn    int
+
+	indents      []int
+	expectIndent bool
+	// continued is true when the logical line continues on the next physical line.
+	continued bool
+
+	// state of the current logical line
+	firstWord   string
+	sawColon    bool
+	lastChar    rune
+	logicalLine int
+}
+
+// checkPythonSyntax returns the syntax issues of the Python source.
+func checkPythonSyntax(file string, source []byte) []Issue {
+	checker := &pythonChecker{file: file, indents: []int{0}}
+	lines := strings.Split(strings.ReplaceAll(string(source), "\r\n", "\n"), "\n")
+	for index, line := range lines {
+		checker.checkLine(index+1, line)
+	}
+	checker.finish(len(lines))
+
+	return checker.issues
+}
+
+func (checker *pythonChecker) report(line int, column int, message string) {
+	checker.issues = append(checker.issues, Issue{
+		Severity: SeverityError,
+		Rule:     "python-syntax",
+		File:     checker.file,
+		Line:     line,
+		Column:   column,
+		Message:  message,
+	})
+}
+
+func (checker *pythonChecker) checkLine(lineNumber int, line string) {
+	runes := []rune(line)
+	start := 0
+
+	newLogicalLine := checker.stringDelimiter == "" && len(checker.brackets) == 0 && !checker.continued
+	if newLogicalLine {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			return
+		}
+
+		start = checker.checkIndentation(lineNumber, runes)
+		checker.firstWord = leadingWord(trimmed)
+		checker.sawColon = false
+		checker.lastChar = 0
+		checker.logicalLine = lineNumber
+	}
+	checker.continued = false
+
+	for column := start; column < len(runes); column++ {
+		char := runes[column]
+
+		if checker.stringDelimiter != "" {
+			if char == '\\' {
+				column++
+				continue
+			}
+			if strings.HasPrefix(string(runes[column:]), checker.stringDelimiter) {
+				column += len(checker.stringDelimiter) - 1
+				checker.stringDelimiter = ""
+				checker.lastChar = char
+			}
+			continue
+		}
+
+		switch char {
+		case '#':
+			column = len(runes)
+			continue
+		case '"', '\'':
+			delimiter := string(char)
+			if strings.HasPrefix(string(runes[column:]), strings.Repeat(delimiter, 3)) {
+				delimiter = strings.Repeat(delimiter, 3)
+			}
+			checker.stringDelimiter = delimiter
+			checker.stringLine, checker.stringColumn = lineNumber, column+1
+			column += len(delimiter) - 1
+			continue
+		case '(', '[', '{':
+			checker.brackets = append(checker.brackets, openBracket{char: char, line: lineNumber, column: column + 1})
+		case ')', ']', '}':
+			if len(checker.brackets) == 0 {
+				checker.report(lineNumber, column+1, fmt.Sprintf("unmatched '%c'", char))
+				break
+			}
+			opening := checker.brackets[len(checker.brackets)-1]
+			checker.brackets = checker.brackets[:len(checker.brackets)-1]
+			if opening.char != closingBrackets[char] {
+				checker.report(lineNumber, column+1, fmt.Sprintf("closing '%c' does not match '%c' opened at line %d", char, opening.char, opening.line))
+			}
+		case ':':
+			if len(checker.brackets) == 0 {
+				checker.sawColon = true
+			}
+		case '\\':
+			if column == len(runes)-1 {
+				checker.continued = true
+				continue
+			}
+		}
+
+		if char != ' ' && char != '\t' {
+			checker.lastChar = char
+		}
+	}
+
+	if len(checker.stringDelimiter) == 1 {
+		if strings.HasSuffix(line, "\\") {
+			checker.continued = true
+			return
+		}
+		checker.report(checker.stringLine, checker.stringColumn, "unterminated string literal")
+		checker.stringDelimiter = ""
+	}
+
+	if checker.stringDelimiter == "" && len(checker.brackets) == 0 && !checker.continued {
+		checker.endLogicalLine()
+	}
+}
+
+// checkIndentation validates the indentation of a new logical line, returning where its content starts.
+func (checker *pythonChecker) checkIndentation(lineNumber int, runes []rune) int {
+	width, start := 0, 0
+	sawSpace, sawTab := false, false
+	for start < len(runes) && (runes[start] == ' ' || runes[start] == '\t') {
+		if runes[start] == '\t' {
+			width += 8 - width%8
+			sawTab = true
+		} else {
+			width++
+			sawSpace = true
+		}
+		start++
+	}
+
+	if sawSpace && sawTab {
+		checker.report(lineNumber, 1, "indentation mixes tabs and spaces")
+	}
+
+	current := checker.indents[len(checker.indents)-1]
+	switch {
+	case checker.expectIndent && width <= current:
+		checker.report(lineNumber, start+1, fmt.Sprintf("expected an indented block after line %d", checker.logicalLine))
+	case !checker.expectIndent && width > current:
+		checker.report(lineNumber, start+1, "unexpected indent")
+	case checker.expectIndent:
+		checker.indents = append(checker.indents, width)
+	case width < current:
+		for len(checker.indents) > 1 && checker.indents[len(checker.indents)-1] > width {
+			checker.indents = checker.indents[:len(checker.indents)-1]
+		}
+		if checker.indents[len(checker.indents)-1] != width {
+			checker.report(lineNumber, start+1, "unindent does not match any outer indentation level")
+			checker.indents = append(checker.indents, width)
+		}
+	}
+	checker.expectIndent = false
+
+	return start
+}
+
+// endLogicalLine checks the complete logical line, e.g. the colon of the block statements.
+func (checker *pythonChecker) endLogicalLine() {
+	checker.expectIndent = checker.lastChar == ':'
+
+	if blockKeywords[checker.firstWord] && !checker.sawColon {
+		checker.report(checker.logicalLine, 1, fmt.Sprintf("expected ':' at the end of the %q statement", checker.firstWord))
+		// assume the block follows anyway, to avoid reporting its indentation too
+		checker.expectIndent = true
+	}
+}
+
+// finish reports the constructs still open at the end of the file.
+func (checker *pythonChecker) finish(lastLine int) {
+	if checker.stringDelimiter != "" {
+		checker.report(checker.stringLine, checker.stringColumn, "unterminated triple-quoted string literal")
+	}
+
+	for _, bracket := range checker.brackets {
+		checker.report(bracket.line, bracket.column, fmt.Sprintf("'%c' was never closed", bracket.char))
+	}
+
+	if checker.expectIndent {
+		checker.report(lastLine, 1, fmt.Sprintf("expected an indented block after line %d", checker.logicalLine))
+	}
+}
+
+// leadingWord returns the identifier at the start of the statement.
+func leadingWord(statement string) string {
+	end := strings.IndexFunc(statement, func(char rune) bool {
+		return !(char == '_' || char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z' || char >= '0' && char <= '9')
+	})
+	if end < 0 {
+		return statement
+	}
+
+	return statement[:end]
+}
@@ -0,0 +1,132 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package plugin
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCheckPythonSyntax(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		// want are the expected issues, formatted as "line:column message".
+		want []string
+	}{
+		{
+			name: "valid plugin",
+			source: `from cat.mad_hatter.decorators import tool, hook
+
+
+@tool
+def get_the_time(tool_input, cat):
+    """Replies to "what time is it", "get the clock" and similar questions."""
+    values = {
+        "a": [1, 2, (3, 4)],  # a comment with an unmatched ( bracket
+    }
+    if values and \
+            tool_input:
+        return f"It's {values['a']}"
+    else:
+        return 'it\'s late'
+
+
+class Settings:
+    pass
+`,
+		},
+		{
+			name:   "windows line endings",
+			source: "def hello():\r\n    return 1\r\n",
+		},
+		{
+			name:   "unterminated string",
+			source: "name = 'cat\nprint(name)\n",
+			want:   []string{"1:8 unterminated string literal"},
+		},
+		{
+			name:   "unterminated triple quoted string",
+			source: "x = 1\ndoc = \"\"\"\nnever closed\n",
+			want:   []string{"2:7 unterminated triple-quoted string literal"},
+		},
+		{
+			name:   "bracket never closed",
+			source: "values = [1, 2,\n    3\n",
+			want:   []string{"1:10 '[' was never closed"},
+		},
+		{
+			name:   "unmatched closing bracket",
+			source: "print(1))\n",
+			want:   []string{"1:9 unmatched ')'"},
+		},
+		{
+			name:   "mismatched brackets",
+			source: "values = [1, 2)\n",
+			want:   []string{"1:15 closing ')' does not match '[' opened at line 1"},
+		},
+		{
+			name:   "missing colon",
+			source: "def hello()\n    return 1\n",
+			want:   []string{"1:1 expected ':' at the end of the \"def\" statement"},
+		},
+		{
+			name:   "missing indented block",
+			source: "if True:\nprint(1)\n",
+			want:   []string{"2:1 expected an indented block after line 1"},
+		},
+		{
+			name:   "missing block at the end of the file",
+			source: "for item in items:",
+			want:   []string{"1:1 expected an indented block after line 1"},
+		},
+		{
+			name:   "unexpected indent",
+			source: "x = 1\n    y = 2\n",
+			want:   []string{"2:5 unexpected indent"},
+		},
+		{
+			name:   "inconsistent unindent",
+			source: "if x:\n        y = 1\n    z = 2\n",
+			want:   []string{"3:5 unindent does not match any outer indentation level"},
+		},
+		{
+			name:   "tabs and spaces",
+			source: "if x:\n\t    y = 1\n",
+			want:   []string{"2:1 indentation mixes tabs and spaces"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			issues := checkPythonSyntax("main.py", []byte(test.source))
+
+			var got []string
+			for _, issue := range issues {
+				if issue.Severity != SeverityError || issue.Rule != "python-syntax" || issue.File != "main.py" {
+					t.Errorf("unexpected issue %+v", issue)
+				}
+				got = append(got, fmt.Sprintf("%d:%d %s", issue.Line, issue.Column, issue.Message))
+			}
+			if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+				t.Errorf("checkPythonSyntax() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package plugin
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultCoreRequirements are the main packages pinned by the cat core (v1.7),
+// which plugins should not pin to different versions.
+var DefaultCoreRequirements = map[string]string{
+	"fastapi":             "0.110.2",
+	"uvicorn":             "0.29.0",
+	"pydantic":            "2.7.1",
+	"langchain":           "0.2.1",
+	"langchain-core":      "0.2.1",
+	"langchain-community": "0.2.1",
+	"langchain-openai":    "0.1.7",
+	"openai":              "1.30.1",
+	"qdrant-client":       "1.9.1",
+	"tiktoken":            "0.7.0",
+	"websockets":          "12.0",
+	"python-multipart":    "0.0.9",
+	"beautifulsoup4":      "4.12.3",
+	"pymupdf":             "1.24.4",
+	"httpx":               "0.27.0",
+	"loguru":              "0.7.2",
+	"apscheduler":         "3.10.4",
+	"scikit-learn":        "1.4.2",
+}
+
+var (
+	requirementPattern      = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)(\[[^\]]*\])?\s*(.*)$`)
+	requirementNameReplacer = regexp.MustCompile(`[-_.]+`)
+	specifierPattern        = regexp.MustCompile(`^(===|==|!=|~=|>=|<=|>|<)\s*(\S+)$`)
+)
+
+// NormalizeRequirementName converts the package name to its normalized form (PEP 503).
+func NormalizeRequirementName(name string) string {
+	return requirementNameReplacer.ReplaceAllString(strings.ToLower(name), "-")
+}
+
+// Requirement represents a line of a requirements.txt file.
+type Requirement struct {
+	// Name is the normalized package name.
+	Name string
+	// Specifier is the version specifier, e.g. "==1.0.0" or ">=1.0,<2".
+	Specifier string
+	Line      int
+}
+
+// Pinned returns the exact version of the requirement, if pinned with == or ===.
+func (requirement Requirement) Pinned() (string, bool) {
+	// === must be checked first, as it starts with ==
+	version, found := strings.CutPrefix(requirement.Specifier, "===")
+	if !found {
+		version, found = strings.CutPrefix(requirement.Specifier, "==")
+	}
+	if !found || strings.ContainsAny(version, "*,") {
+		return "", false
+	}
+
+	return strings.TrimSpace(version), true
+}
+
+// ParseRequirements reads the package requirements, skipping comments, options and URLs.
+func ParseRequirements(reader io.Reader) ([]Requirement, error) {
+	var requirements []Requirement
+
+	scanner := bufio.NewScanner(reader)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+		if index := strings.Index(line, "#"); index >= 0 {
+			line = line[:index]
+		}
+		if index := strings.Index(line, ";"); index >= 0 {
+			line = line[:index]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "-") || strings.Contains(line, "://") {
+			continue
+		}
+
+		match := requirementPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		requirements = append(requirements, Requirement{
+			Name:      NormalizeRequirementName(match[1]),
+			Specifier: strings.ReplaceAll(match[3], " ", ""),
+			Line:      lineNumber,
+		})
+	}
+
+	return requirements, scanner.Err()
+}
+
+// lintRequirements checks that the requirements are pinned and compatible with the cat core.
+func lintRequirements(folder string, coreRequirements map[string]string) ([]Issue, error) {
+	const file = "requirements.txt"
+
+	requirementsFile, err := os.Open(filepath.Join(folder, file))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer requirementsFile.Close()
+
+	requirements, err := ParseRequirements(requirementsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	for _, requirement := range requirements {
+		coreVersion, inCore := coreRequirements[requirement.Name]
+
+		switch {
+		case inCore && requirement.Specifier != "" && !satisfiesSpecifier(coreVersion, requirement.Specifier):
+			issues = append(issues, Issue{
+				Severity: SeverityError,
+				Rule:     "requirements-conflict",
+				File:     file,
+				Line:     requirement.Line,
+				Message:  fmt.Sprintf("%s%s conflicts with the version %s pinned by the cat core", requirement.Name, requirement.Specifier, coreVersion),
+			})
+		case inCore:
+			issues = append(issues, Issue{
+				Severity: SeverityInfo,
+				Rule:     "requirements-core",
+				File:     file,
+				Line:     requirement.Line,
+				Message:  fmt.Sprintf("%s is already provided by the cat core (%s), it can be removed", requirement.Name, coreVersion),
+			})
+		case requirement.Specifier == "":
+			issues = append(issues, Issue{
+				Severity: SeverityWarning,
+				Rule:     "requirements-unpinned",
+				File:     file,
+				Line:     requirement.Line,
+				Message:  fmt.Sprintf("%s is not pinned, use %s==<version> for reproducible installs", requirement.Name, requirement.Name),
+			})
+		default:
+			if _, pinned := requirement.Pinned(); !pinned {
+				issues = append(issues, Issue{
+					Severity: SeverityInfo,
+					Rule:     "requirements-unpinned",
+					File:     file,
+					Line:     requirement.Line,
+					Message:  fmt.Sprintf("%s%s is not pinned to an exact version", requirement.Name, requirement.Specifier),
+				})
+			}
+		}
+	}
+
+	return issues, nil
+}
+
+// satisfiesSpecifier checks whether the version matches all the comma separated clauses of the specifier.
+//
+// Clauses which cannot be parsed are considered satisfied, to avoid false conflicts.
+func satisfiesSpecifier(version string, specifier string) bool {
+	for _, clause := range strings.Split(specifier, ",") {
+		match := specifierPattern.FindStringSubmatch(strings.TrimSpace(clause))
+		if match == nil {
+			continue
+		}
+
+		operator, clauseVersion := match[1], match[2]
+		if prefix, isWildcard := strings.CutSuffix(clauseVersion, ".*"); isWildcard {
+			matchesPrefix := version == prefix || strings.HasPrefix(version, prefix+".")
+			if (operator == "==" && !matchesPrefix) || (operator == "!=" && matchesPrefix) {
+				return false
+			}
+			continue
+		}
+
+		comparison := compareVersions(version, clauseVersion)
+		satisfied := true
+		switch operator {
+		case "==", "===":
+			satisfied = comparison == 0
+		case "!=":
+			satisfied = comparison != 0
+		case ">=":
+			satisfied = comparison >= 0
+		case "<=":
+			satisfied = comparison <= 0
+		case ">":
+			satisfied = comparison > 0
+		case "<":
+			satisfied = comparison < 0
+		case "~=":
+			// ~=X.Y.Z means >=X.Y.Z and ==X.Y.*
+			parts := strings.Split(clauseVersion, ".")
+			prefix := strings.Join(parts[:max(len(parts)-1, 1)], ".")
+			satisfied = comparison >= 0 && (version == prefix || strings.HasPrefix(version, prefix+"."))
+		}
+		if !satisfied {
+			return false
+		}
+	}
+
+	return true
+}
+
+// compareVersions compares two dotted versions, numerically when possible.
+func compareVersions(a string, b string) int {
+	partsA, partsB := strings.Split(a, "."), strings.Split(b, ".")
+	for index := 0; index < max(len(partsA), len(partsB)); index++ {
+		partA, partB := "0", "0"
+		if index < len(partsA) {
+			partA = partsA[index]
+		}
+		if index < len(partsB) {
+			partB = partsB[index]
+		}
+
+		numberA, errA := strconv.Atoi(partA)
+		numberB, errB := strconv.Atoi(partB)
+		switch {
+		case errA == nil && errB == nil && numberA != numberB:
+			if numberA < numberB {
+				return -1
+			}
+			return 1
+		case (errA != nil || errB != nil) && partA != partB:
+			return strings.Compare(partA, partB)
+		}
+	}
+
+	return 0
+}
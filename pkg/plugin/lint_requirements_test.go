@@ -0,0 +1,139 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package plugin
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRequirements(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []Requirement
+	}{
+		{name: "empty", content: ""},
+		{
+			name:    "pinned and unpinned",
+			content: "requests==2.31.0\nPyYAML\n",
+			want: []Requirement{
+				{Name: "requests", Specifier: "==2.31.0", Line: 1},
+				{Name: "pyyaml", Line: 2},
+			},
+		},
+		{
+			name:    "normalized names",
+			content: "Scikit_Learn>=1.4\nzope.interface~=6.0",
+			want: []Requirement{
+				{Name: "scikit-learn", Specifier: ">=1.4", Line: 1},
+				{Name: "zope-interface", Specifier: "~=6.0", Line: 2},
+			},
+		},
+		{
+			name:    "extras, spaces and ranges",
+			content: "uvicorn[standard] >= 0.29, < 1",
+			want:    []Requirement{{Name: "uvicorn", Specifier: ">=0.29,<1", Line: 1}},
+		},
+		{
+			name:    "comments and environment markers",
+			content: "# the HTTP client\nhttpx==0.27.0  # pinned by the core\ncolorama==0.4.6; sys_platform == 'win32'",
+			want: []Requirement{
+				{Name: "httpx", Specifier: "==0.27.0", Line: 2},
+				{Name: "colorama", Specifier: "==0.4.6", Line: 3},
+			},
+		},
+		{
+			name:    "options and URLs are skipped",
+			content: "-r base.txt\n--index-url https://example.com/simple\ngit+https://github.com/user/repo.git\n\nrich==13.7.1",
+			want:    []Requirement{{Name: "rich", Specifier: "==13.7.1", Line: 5}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseRequirements(strings.NewReader(test.content))
+			if err != nil {
+				t.Fatalf("ParseRequirements() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("ParseRequirements() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRequirementPinned(t *testing.T) {
+	tests := []struct {
+		specifier  string
+		want       string
+		wantPinned bool
+	}{
+		{specifier: "==2.7.1", want: "2.7.1", wantPinned: true},
+		{specifier: "===2.7.1", want: "2.7.1", wantPinned: true},
+		{specifier: "", wantPinned: false},
+		{specifier: ">=2.7", wantPinned: false},
+		{specifier: "~=2.7.1", wantPinned: false},
+		{specifier: "==2.*", wantPinned: false},
+		{specifier: "==2.7.1,!=2.7.0", wantPinned: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.specifier, func(t *testing.T) {
+			got, pinned := Requirement{Name: "pydantic", Specifier: test.specifier}.Pinned()
+			if got != test.want || pinned != test.wantPinned {
+				t.Errorf("Pinned() = %q, %v, want %q, %v", got, pinned, test.want, test.wantPinned)
+			}
+		})
+	}
+}
+
+func TestSatisfiesSpecifier(t *testing.T) {
+	tests := []struct {
+		version   string
+		specifier string
+		want      bool
+	}{
+		{version: "2.7.1", specifier: "==2.7.1", want: true},
+		{version: "2.7.1", specifier: "===2.7.1", want: true},
+		{version: "2.7.1", specifier: "==2.6.0", want: false},
+		{version: "2.7.1", specifier: "!=2.7.1", want: false},
+		{version: "2.7.1", specifier: ">=2.0,<3", want: true},
+		{version: "2.7.1", specifier: ">=2.0,<2.5", want: false},
+		{version: "2.7.1", specifier: ">2.7.1", want: false},
+		{version: "2.7.1", specifier: "<=2.7.1", want: true},
+		{version: "0.10.0", specifier: ">0.9.0", want: true},
+		{version: "2.7.1", specifier: "==2.7.*", want: true},
+		{version: "2.7.1", specifier: "==2.6.*", want: false},
+		{version: "2.7.1", specifier: "!=2.7.*", want: false},
+		{version: "2.7.1", specifier: "~=2.7.0", want: true},
+		{version: "2.7.1", specifier: "~=2.6.0", want: false},
+		{version: "2.7.1", specifier: "~=2.6", want: true},
+		{version: "2.7.1", specifier: "@latest", want: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.version+test.specifier, func(t *testing.T) {
+			got := satisfiesSpecifier(test.version, test.specifier)
+			if got != test.want {
+				t.Errorf("satisfiesSpecifier(%q, %q) = %v, want %v", test.version, test.specifier, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LockfileName is the default name of the plugin lockfile.
+const LockfileName = "meow-plugins.lock"
+
+// lockfileVersion is the version of the lockfile format written by this CLI.
+const lockfileVersion = 1
+
+// RegistrySourcePrefix marks the lockfile sources referring to a registry plugin (registry:<name or URL>).
+const RegistrySourcePrefix = "registry:"
+
+// Lockfile represents the set of plugins an instance must run.
+type Lockfile struct {
+	Version int            `yaml:"version" json:"version"`
+	Plugins []LockedPlugin `yaml:"plugins" json:"plugins"`
+}
+
+// LockedPlugin represents a plugin of the lockfile.
+type LockedPlugin struct {
+	// ID is the plugin id, i.e. the name of its folder in the cat.
+	ID      string `yaml:"id" json:"id"`
+	Version string `yaml:"version,omitempty" json:"version,omitempty"`
+	// Source is where the plugin is installed from: a folder, an archive,
+	// a git URL (with an optional #ref) or registry:<name or URL>.
+	Source string `yaml:"source" json:"source"`
+	// SHA256 is the checksum of the archive uploaded to the cat, verified before installing when set.
+	SHA256 string `yaml:"sha256,omitempty" json:"sha256,omitempty"`
+	Active bool   `yaml:"active" json:"active"`
+}
+
+// ReadLockfile reads and validates the lockfile at path.
+func ReadLockfile(path string) (*Lockfile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	lockfile := new(Lockfile)
+	err = yaml.Unmarshal(content, lockfile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lockfile %q: %w", path, err)
+	}
+
+	if lockfile.Version > lockfileVersion {
+		return nil, fmt.Errorf("lockfile %q has version %d, this meow version supports up to %d", path, lockfile.Version, lockfileVersion)
+	}
+
+	seen := make(map[string]bool, len(lockfile.Plugins))
+	for index, locked := range lockfile.Plugins {
+		if locked.ID == "" {
+			return nil, fmt.Errorf("invalid lockfile %q: plugin #%d has no id", path, index+1)
+		}
+		if seen[locked.ID] {
+			return nil, fmt.Errorf("invalid lockfile %q: plugin %q is listed more than once", path, locked.ID)
+		}
+		seen[locked.ID] = true
+	}
+
+	return lockfile, nil
+}
+
+// Find returns the locked plugin with the specified id.
+func (lockfile *Lockfile) Find(id string) (*LockedPlugin, bool) {
+	for index := range lockfile.Plugins {
+		if lockfile.Plugins[index].ID == id {
+			return &lockfile.Plugins[index], true
+		}
+	}
+
+	return nil, false
+}
+
+// Write saves the lockfile at path, with the plugins sorted by id.
+func (lockfile *Lockfile) Write(path string) error {
+	lockfile.Version = lockfileVersion
+	sort.Slice(lockfile.Plugins, func(i, j int) bool {
+		return lockfile.Plugins[i].ID < lockfile.Plugins[j].ID
+	})
+
+	var content bytes.Buffer
+	content.WriteString("# Generated by meow plugin freeze, apply it with meow plugin sync.\n")
+
+	encoder := yaml.NewEncoder(&content)
+	encoder.SetIndent(2)
+	err := encoder.Encode(lockfile)
+	if err != nil {
+		return err
+	}
+	err = encoder.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, content.Bytes(), 0o644)
+}
@@ -0,0 +1,95 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// ManifestFile is the name of the plugin manifest.
+const ManifestFile = "plugin.json"
+
+// Manifest represents the plugin.json file of a plugin.
+type Manifest struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	AuthorName  string `json:"author_name"`
+	AuthorURL   string `json:"author_url"`
+	PluginURL   string `json:"plugin_url"`
+	Tags        string `json:"tags"`
+	Thumb       string `json:"thumb"`
+}
+
+// ReadManifest reads the plugin.json file of the plugin folder.
+func ReadManifest(folder string) (*Manifest, error) {
+	content, err := os.ReadFile(filepath.Join(folder, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := new(Manifest)
+	err = json.Unmarshal(content, manifest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ManifestFile, err)
+	}
+
+	return manifest, nil
+}
+
+var manifestVersionPattern = regexp.MustCompile(`("version"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+// StampVersion returns the plugin.json content with the version replaced,
+// keeping the rest of the file untouched when possible.
+func StampVersion(content []byte, version string) ([]byte, error) {
+	encodedVersion, err := json.Marshal(version)
+	if err != nil {
+		return nil, err
+	}
+
+	if manifestVersionPattern.Match(content) {
+		replaced := false
+		return manifestVersionPattern.ReplaceAllFunc(content, func(match []byte) []byte {
+			if replaced {
+				return match
+			}
+			replaced = true
+
+			prefix := manifestVersionPattern.FindSubmatch(match)[1]
+			return append(append([]byte{}, prefix...), encodedVersion...)
+		}), nil
+	}
+
+	var manifest map[string]any
+	err = json.Unmarshal(content, &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ManifestFile, err)
+	}
+	manifest["version"] = version
+
+	stamped, err := json.MarshalIndent(manifest, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(stamped, '\n'), nil
+}
@@ -0,0 +1,122 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package plugin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// PackConfig represents the parameters used to build a plugin release archive.
+type PackConfig struct {
+	// Output is the path of the zip archive to create.
+	Output string
+	// Version, if set, is stamped into the plugin.json of the archive (the plugin folder is not modified).
+	Version string
+}
+
+// PackResult represents the plugin release archive built by Pack.
+type PackResult struct {
+	Archive  string `json:"archive"`
+	Checksum string `json:"checksum_file"`
+	SHA256   string `json:"sha256"`
+	Version  string `json:"version"`
+}
+
+// Pack builds the reproducible zip archive of the plugin folder and writes its SHA-256 checksum
+// next to it, in the sha256sum format (<archive>.sha256).
+func Pack(folder string, config PackConfig) (*PackResult, error) {
+	folder, err := filepath.Abs(folder)
+	if err != nil {
+		return nil, err
+	}
+	output, err := filepath.Abs(config.Output)
+	if err != nil {
+		return nil, err
+	}
+	checksumPath := output + ".sha256"
+
+	manifestContent, err := os.ReadFile(filepath.Join(folder, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := ReadManifest(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	zipConfig := ZipConfig{Exclude: []string{output, checksumPath}}
+	version := manifest.Version
+	if config.Version != "" {
+		stamped, err := StampVersion(manifestContent, config.Version)
+		if err != nil {
+			return nil, err
+		}
+		zipConfig.Overrides = map[string][]byte{ManifestFile: stamped}
+		version = config.Version
+	}
+
+	err = os.MkdirAll(filepath.Dir(output), 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	// the archive is written to a temporary file, to never leave a partial archive behind
+	tempFile, err := os.CreateTemp(filepath.Dir(output), ".meow-pack-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tempFile.Name())
+	zipConfig.Exclude = append(zipConfig.Exclude, tempFile.Name())
+
+	hash := sha256.New()
+	err = WriteZip(folder, filepath.Base(folder), io.MultiWriter(tempFile, hash), zipConfig)
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.Chmod(tempFile.Name(), 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.Rename(tempFile.Name(), output)
+	if err != nil {
+		return nil, err
+	}
+
+	checksum := hex.EncodeToString(hash.Sum(nil))
+	err = os.WriteFile(checksumPath, []byte(fmt.Sprintf("%s  %s\n", checksum, filepath.Base(output))), 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PackResult{
+		Archive:  output,
+		Checksum: checksumPath,
+		SHA256:   checksum,
+		Version:  version,
+	}, nil
+}
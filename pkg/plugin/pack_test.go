@@ -0,0 +1,171 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package plugin
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp/syntax"
+	"testing"
+	"time"
+
+	"github.com/saniales/meow-cli/internal/testutil"
+)
+
+func TestPackReproducible(t *testing.T) {
+	folder := filepath.Join(t.TempDir(), "my_plugin")
+	testutil.WriteFiles(t, folder, map[string]string{
+		"plugin.json":        `{"name": "My Plugin", "version": "0.1.0"}`,
+		"my_plugin.py":       "from cat.mad_hatter.decorators import tool\n",
+		"lib/helper.py":      "pass\n",
+		"requirements.txt":   "rich==13.7.1\n",
+		".gitignore":         "*.log\nbuild/\n",
+		"debug.log":          "ignored",
+		"build/output.txt":   "ignored",
+		"__pycache__/x.pyc":  "ignored",
+		".meowignore":        "secrets.txt\n",
+		"secrets.txt":        "ignored",
+		"static/picture.png": "png",
+	})
+
+	first, err := Pack(folder, PackConfig{Output: filepath.Join(t.TempDir(), "first.zip"), Version: "1.0.0"})
+	if err != nil {
+		t.Fatalf("Pack() error = %v", err)
+	}
+
+	// the modification times must not affect the archive
+	later := time.Now().Add(time.Hour)
+	err = os.Chtimes(filepath.Join(folder, "my_plugin.py"), later, later)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// neither an archive placed inside the plugin folder
+	second, err := Pack(folder, PackConfig{Output: filepath.Join(folder, "dist", "second.zip"), Version: "1.0.0"})
+	if err != nil {
+		t.Fatalf("Pack() error = %v", err)
+	}
+
+	firstContent, err := os.ReadFile(first.Archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secondContent, err := os.ReadFile(second.Archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(firstContent, secondContent) || first.SHA256 != second.SHA256 {
+		t.Errorf("the archives of the same folder differ, SHA-256 %s and %s", first.SHA256, second.SHA256)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(firstContent), int64(len(firstContent)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, file := range reader.File {
+		names = append(names, file.Name)
+	}
+	want := []string{
+		"my_plugin/.gitignore",
+		"my_plugin/.meowignore",
+		"my_plugin/lib/helper.py",
+		"my_plugin/my_plugin.py",
+		"my_plugin/plugin.json",
+		"my_plugin/requirements.txt",
+		"my_plugin/static/picture.png",
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("archive entries = %v, want %v", names, want)
+	}
+}
+
+func TestPackInvalidIgnorePattern(t *testing.T) {
+	folder := filepath.Join(t.TempDir(), "my_plugin")
+	testutil.WriteFiles(t, folder, map[string]string{
+		"plugin.json": `{"name": "My Plugin", "version": "0.1.0"}`,
+		".gitignore":  "foo[z-a].txt\n",
+	})
+
+	_, err := Pack(folder, PackConfig{Output: filepath.Join(t.TempDir(), "plugin.zip")})
+	if err == nil {
+		t.Fatal("Pack() error = nil, want an invalid pattern error")
+	}
+}
+
+func TestIgnoreMatcher(t *testing.T) {
+	matcher, err := NewIgnoreMatcher([]string{
+		"# comment",
+		"",
+		"*.log",
+		"!keep.log",
+		"/root_only.txt",
+		"build/",
+		"docs/**/*.md",
+		`\#literal`,
+		"file[0-9].txt",
+	})
+	if err != nil {
+		t.Fatalf("NewIgnoreMatcher() error = %v", err)
+	}
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{path: "debug.log", want: true},
+		{path: "deep/nested/debug.log", want: true},
+		{path: "keep.log", want: false},
+		{path: "root_only.txt", want: true},
+		{path: "sub/root_only.txt", want: false},
+		{path: "build", isDir: true, want: true},
+		{path: "build", isDir: false, want: false},
+		{path: "docs/a/b/readme.md", want: true},
+		{path: "docs/readme.md", want: true},
+		{path: "readme.md", want: false},
+		{path: "#literal", want: true},
+		{path: "file1.txt", want: true},
+		{path: "filex.txt", want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			got := matcher.Match(test.path, test.isDir)
+			if got != test.want {
+				t.Errorf("Match(%q, %v) = %v, want %v", test.path, test.isDir, got, test.want)
+			}
+		})
+	}
+}
+
+func TestNewIgnoreMatcherInvalidPattern(t *testing.T) {
+	_, err := NewIgnoreMatcher([]string{"*.log", "foo[z-a].txt"})
+	if err == nil {
+		t.Fatal("NewIgnoreMatcher() error = nil, want an error")
+	}
+
+	var syntaxErr *syntax.Error
+	if !errors.As(err, &syntaxErr) {
+		t.Errorf("NewIgnoreMatcher() error = %v, want it to wrap the regexp error", err)
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/saniales/meow-cli/gen/bindata"
+)
+
+// templatesFolder is the embedded folder containing the plugin skeleton templates.
+const templatesFolder = "plugin-templates"
+
+// ScaffoldConfig represents the values used to generate a plugin skeleton.
+type ScaffoldConfig struct {
+	Name        string
+	Author      string
+	AuthorURL   string
+	PluginURL   string
+	Description string
+	Version     string
+	// Hooks, Tools and Forms select the examples included in the skeleton.
+	Hooks bool
+	Tools bool
+	Forms bool
+}
+
+// scaffoldData is the data passed to the plugin templates.
+type scaffoldData struct {
+	ScaffoldConfig
+	ID        string
+	ClassName string
+}
+
+var nonIdentifierChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// PluginID converts the plugin name in the snake case identifier used for its folder and Python modules.
+func PluginID(name string) string {
+	id := strings.Trim(nonIdentifierChars.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if id != "" && unicode.IsDigit(rune(id[0])) {
+		id = "plugin_" + id
+	}
+
+	return id
+}
+
+// className converts the plugin id in the CamelCase name used for its Python classes.
+func className(id string) string {
+	var name strings.Builder
+	for _, word := range strings.Split(id, "_") {
+		if word == "" {
+			continue
+		}
+		name.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+
+	return name.String()
+}
+
+// Scaffold generates the plugin skeleton in the folder, which must be missing or empty.
+//
+// Templates rendering to an empty content (e.g. optional examples not selected) are skipped.
+func Scaffold(folder string, config ScaffoldConfig) ([]string, error) {
+	id := PluginID(config.Name)
+	if id == "" {
+		return nil, ErrEmptyPluginName
+	}
+	if config.Version == "" {
+		config.Version = "0.0.1"
+	}
+
+	entries, err := os.ReadDir(folder)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if len(entries) > 0 {
+		return nil, ErrFolderNotEmpty(folder)
+	}
+
+	data := scaffoldData{
+		ScaffoldConfig: config,
+		ID:             id,
+		ClassName:      className(id),
+	}
+
+	assetNames := bindata.AssetNames()
+	sort.Strings(assetNames)
+
+	var created []string
+	for _, assetName := range assetNames {
+		relativePath, isTemplate := strings.CutPrefix(assetName, templatesFolder+"/")
+		if !isTemplate {
+			continue
+		}
+
+		content, err := renderTemplate(assetName, data)
+		if err != nil {
+			return created, err
+		}
+		if len(bytes.TrimSpace(content)) == 0 {
+			continue
+		}
+
+		relativePath = strings.TrimSuffix(relativePath, ".tmpl")
+		filePath := filepath.Join(folder, filepath.FromSlash(relativePath))
+		err = os.MkdirAll(filepath.Dir(filePath), 0o755)
+		if err != nil {
+			return created, err
+		}
+
+		err = os.WriteFile(filePath, content, 0o644)
+		if err != nil {
+			return created, err
+		}
+		created = append(created, relativePath)
+	}
+
+	return created, nil
+}
+
+// renderTemplate executes the embedded template with the scaffold data.
+func renderTemplate(assetName string, data scaffoldData) ([]byte, error) {
+	source, err := bindata.Asset(assetName)
+	if err != nil {
+		return nil, err
+	}
+
+	fileTemplate, err := template.New(path.Base(assetName)).Funcs(template.FuncMap{
+		"json": func(value any) (string, error) {
+			encoded, err := json.Marshal(value)
+			return string(encoded), err
+		},
+	}).Parse(string(source))
+	if err != nil {
+		return nil, err
+	}
+
+	var content bytes.Buffer
+	err = fileTemplate.Execute(&content, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return content.Bytes(), nil
+}
@@ -0,0 +1,119 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package plugin
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// catWrittenFiles are written by the cat into its copy of the plugin folder,
+// so they are kept even if missing from the plugin folder.
+var catWrittenFiles = map[string]bool{
+	"settings.json": true,
+}
+
+// SyncFolder mirrors the plugin folder into the destination one, creating it if missing.
+//
+// Only the files whose content differs are copied, and the files missing from the plugin folder
+// are removed from the destination, except the ones written by the cat (e.g. settings.json).
+// The skipped folders (e.g. .git) are ignored on both sides.
+// It returns the relative paths of the copied and removed files.
+func SyncFolder(folder string, destination string) ([]string, error) {
+	sourceFiles, err := listFiles(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	destinationFiles, err := listFiles(destination)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var changed []string
+	for relativePath := range sourceFiles {
+		sourcePath := filepath.Join(folder, relativePath)
+		destinationPath := filepath.Join(destination, relativePath)
+
+		content, err := os.ReadFile(sourcePath)
+		if err != nil {
+			return changed, err
+		}
+
+		if destinationFiles[relativePath] {
+			current, err := os.ReadFile(destinationPath)
+			if err == nil && bytes.Equal(current, content) {
+				continue
+			}
+		}
+
+		err = os.MkdirAll(filepath.Dir(destinationPath), 0o755)
+		if err != nil {
+			return changed, err
+		}
+
+		err = os.WriteFile(destinationPath, content, 0o644)
+		if err != nil {
+			return changed, err
+		}
+		changed = append(changed, filepath.ToSlash(relativePath))
+	}
+
+	for relativePath := range destinationFiles {
+		if sourceFiles[relativePath] || catWrittenFiles[filepath.ToSlash(relativePath)] {
+			continue
+		}
+
+		err = os.Remove(filepath.Join(destination, relativePath))
+		if err != nil && !os.IsNotExist(err) {
+			return changed, err
+		}
+		changed = append(changed, filepath.ToSlash(relativePath))
+	}
+
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// listFiles returns the set of the regular files in the folder, relative to it.
+func listFiles(folder string) (map[string]bool, error) {
+	files := make(map[string]bool)
+	err := filepath.WalkDir(folder, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() && filePath != folder && skippedFolders[entry.Name()] {
+			return filepath.SkipDir
+		}
+
+		if entry.Type().IsRegular() {
+			relativePath, err := filepath.Rel(folder, filePath)
+			if err != nil {
+				return err
+			}
+			files[relativePath] = true
+		}
+
+		return nil
+	})
+
+	return files, err
+}
@@ -0,0 +1,67 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/saniales/meow-cli/internal/testutil"
+)
+
+func TestSyncFolder(t *testing.T) {
+	folder := t.TempDir()
+	destination := t.TempDir()
+
+	testutil.WriteFiles(t, folder, map[string]string{
+		"plugin.json":   `{"name": "Test"}`,
+		"main.py":       "print('new')",
+		"lib/helper.py": "pass",
+	})
+	testutil.WriteFiles(t, destination, map[string]string{
+		"plugin.json":   `{"name": "Test"}`,
+		"main.py":       "print('old')",
+		"removed.py":    "pass",
+		"settings.json": `{"temperature": 0.5}`,
+	})
+
+	changed, err := SyncFolder(folder, destination)
+	if err != nil {
+		t.Fatalf("SyncFolder() error = %v", err)
+	}
+
+	want := []string{"lib/helper.py", "main.py", "removed.py"}
+	if !reflect.DeepEqual(changed, want) {
+		t.Errorf("SyncFolder() = %v, want %v", changed, want)
+	}
+
+	if _, err := os.Stat(filepath.Join(destination, "removed.py")); !os.IsNotExist(err) {
+		t.Errorf("removed.py still exists in the destination, error = %v", err)
+	}
+	settings, err := os.ReadFile(filepath.Join(destination, "settings.json"))
+	if err != nil || string(settings) != `{"temperature": 0.5}` {
+		t.Errorf("settings.json = %q (error %v), want it untouched", settings, err)
+	}
+
+	changed, err = SyncFolder(folder, destination)
+	if err != nil || len(changed) != 0 {
+		t.Errorf("second SyncFolder() = %v, %v, want no changes", changed, err)
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package plugin
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watch calls onChange with the relative paths of the changed files every time the plugin folder changes,
+// until the context is done.
+//
+// Changes are debounced: onChange is called once the folder has not changed for the debounce duration.
+// Subfolders are watched recursively, except for the skipped ones and editor temporary files.
+func Watch(ctx context.Context, folder string, debounce time.Duration, onChange func(changed []string)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	err = watchFolders(watcher, folder)
+	if err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	pending := make(map[string]bool)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return err
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			relativePath, err := filepath.Rel(folder, event.Name)
+			if err != nil || isIgnoredChange(relativePath) {
+				continue
+			}
+
+			if event.Has(fsnotify.Create) {
+				info, err := os.Stat(event.Name)
+				if err == nil && info.IsDir() {
+					err = watchFolders(watcher, event.Name)
+					if err != nil {
+						return err
+					}
+				}
+			}
+
+			pending[filepath.ToSlash(relativePath)] = true
+			timer.Reset(debounce)
+
+		case <-timer.C:
+			changed := make([]string, 0, len(pending))
+			for relativePath := range pending {
+				changed = append(changed, relativePath)
+			}
+			sort.Strings(changed)
+			clear(pending)
+
+			onChange(changed)
+		}
+	}
+}
+
+// watchFolders adds the folder and all its subfolders to the watcher.
+func watchFolders(watcher *fsnotify.Watcher, folder string) error {
+	return filepath.WalkDir(folder, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if filePath != folder && skippedFolders[entry.Name()] {
+			return filepath.SkipDir
+		}
+
+		return watcher.Add(filePath)
+	})
+}
+
+// isIgnoredChange checks whether the changed path is inside a skipped folder or is an editor temporary file.
+func isIgnoredChange(relativePath string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(relativePath), "/") {
+		if skippedFolders[part] {
+			return true
+		}
+	}
+
+	name := filepath.Base(relativePath)
+	return strings.HasSuffix(name, "~") ||
+		strings.HasSuffix(name, ".swp") ||
+		strings.HasSuffix(name, ".swx") ||
+		strings.HasSuffix(name, ".pyc") ||
+		strings.HasPrefix(name, ".#") ||
+		name == "4913"
+}
@@ -0,0 +1,186 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package cat contains the client for the Cheshire Cat REST API.
+package cat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type httpClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client is a typed client for the Cheshire Cat REST API.
+type Client struct {
+	httpClient httpClient
+	baseURL    *url.URL
+	apiKey     string
+	userID     string
+}
+
+// ClientConfig represents the parameters used to connect to the cat API.
+type ClientConfig struct {
+	// BaseURL is the URL of the cat API, e.g. http://localhost:1865.
+	BaseURL string
+	// APIKey is sent as bearer token, if not empty.
+	APIKey string
+	// UserID is sent in the user_id header, if not empty.
+	UserID string
+}
+
+// NewClient creates a new Client with the given httpClient and config.
+//
+// Parameters:
+// - httpClient: The httpClient to be used by the Client.
+// - config: The parameters used to connect to the cat API.
+//
+// Returns:
+// - *Client: A pointer to the newly created Client.
+// - error: An error if the httpClient is nil or the base URL is not valid.
+func NewClient(httpClient httpClient, config ClientConfig) (*Client, error) {
+	if httpClient == nil {
+		return nil, ErrNilHTTPClient
+	}
+
+	baseURL, err := url.Parse(config.BaseURL)
+	if err != nil || (baseURL.Scheme != "http" && baseURL.Scheme != "https") || baseURL.Host == "" {
+		return nil, ErrInvalidBaseURL(config.BaseURL)
+	}
+
+	return &Client{
+		httpClient: httpClient,
+		baseURL:    baseURL,
+		apiKey:     config.APIKey,
+		userID:     config.UserID,
+	}, nil
+}
+
+// BaseURL returns the URL of the cat API.
+func (client *Client) BaseURL() *url.URL {
+	baseURL := *client.baseURL
+	return &baseURL
+}
+
+// doJSON performs a request with an optional JSON body, decoding the JSON response into result (if not nil).
+func (client *Client) doJSON(ctx context.Context, method string, path string, query url.Values, body any, result any) error {
+	var bodyReader io.Reader
+	contentType := ""
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+
+		bodyReader = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+
+	return client.do(ctx, method, path, query, bodyReader, contentType, result)
+}
+
+// doMultipart performs a multipart POST request uploading the file in the specified form field,
+// along with the additional form fields, decoding the JSON response into result (if not nil).
+func (client *Client) doMultipart(ctx context.Context, path string, fieldName string, fileName string, file io.Reader, fields map[string]string, result any) error {
+	bodyReader, bodyWriter := io.Pipe()
+	form := multipart.NewWriter(bodyWriter)
+
+	go func() {
+		for name, value := range fields {
+			err := form.WriteField(name, value)
+			if err != nil {
+				bodyWriter.CloseWithError(err)
+				return
+			}
+		}
+
+		part, err := form.CreateFormFile(fieldName, fileName)
+		if err != nil {
+			bodyWriter.CloseWithError(err)
+			return
+		}
+
+		_, err = io.Copy(part, file)
+		if err != nil {
+			bodyWriter.CloseWithError(err)
+			return
+		}
+
+		bodyWriter.CloseWithError(form.Close())
+	}()
+
+	err := client.do(ctx, http.MethodPost, path, nil, bodyReader, form.FormDataContentType(), result)
+	bodyReader.Close()
+
+	return err
+}
+
+// do performs the request, returning a *NetworkError if the status code is not successful.
+//
+// The response body is copied to result if it is an io.Writer, otherwise it is decoded as JSON.
+func (client *Client) do(ctx context.Context, method string, path string, query url.Values, body io.Reader, contentType string, result any) error {
+	requestURL := client.baseURL.JoinPath(path)
+	if strings.HasSuffix(path, "/") && !strings.HasSuffix(requestURL.Path, "/") {
+		requestURL.Path += "/"
+	}
+	requestURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, method, requestURL.String(), body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if client.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+client.apiKey)
+	}
+	if client.userID != "" {
+		req.Header.Set("user_id", client.userID)
+	}
+
+	resp, err := client.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newNetworkError(req, resp)
+	}
+
+	if result == nil {
+		_, err = io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	if writer, ok := result.(io.Writer); ok {
+		_, err = io.Copy(writer, resp.Body)
+		return err
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
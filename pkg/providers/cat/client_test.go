@@ -0,0 +1,527 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// newTestClient starts a stand-in cat server with the handler, returning a client pointing to it.
+func newTestClient(t *testing.T, config ClientConfig, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	config.BaseURL = server.URL
+	client, err := NewClient(server.Client(), config)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	return client
+}
+
+// writeJSON writes the value as JSON response body.
+func writeJSON(t *testing.T, w http.ResponseWriter, statusCode int, value any) {
+	t.Helper()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	err := json.NewEncoder(w).Encode(value)
+	if err != nil {
+		t.Fatalf("cannot write response: %v", err)
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	tests := []struct {
+		name       string
+		httpClient httpClient
+		baseURL    string
+		wantErr    bool
+	}{
+		{name: "valid", httpClient: http.DefaultClient, baseURL: "http://localhost:1865"},
+		{name: "nil http client", httpClient: nil, baseURL: "http://localhost:1865", wantErr: true},
+		{name: "missing scheme", httpClient: http.DefaultClient, baseURL: "localhost:1865", wantErr: true},
+		{name: "unsupported scheme", httpClient: http.DefaultClient, baseURL: "ftp://localhost", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewClient(test.httpClient, ClientConfig{BaseURL: test.baseURL})
+			if (err != nil) != test.wantErr {
+				t.Errorf("NewClient() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestClientStatus(t *testing.T) {
+	client := newTestClient(t, ClientConfig{APIKey: "secret", UserID: "alice"}, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization header = %q, want %q", got, "Bearer secret")
+		}
+		if got := r.Header.Get("user_id"); got != "alice" {
+			t.Errorf("user_id header = %q, want %q", got, "alice")
+		}
+
+		writeJSON(t, w, http.StatusOK, map[string]string{"status": "We're all mad here, dear!", "version": "1.7.1"})
+	})
+
+	status, err := client.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if status.Version != "1.7.1" {
+		t.Errorf("Status().Version = %q, want %q", status.Version, "1.7.1")
+	}
+}
+
+func TestClientNetworkErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		wantIs     error
+		wantDetail string
+	}{
+		{name: "not found", statusCode: http.StatusNotFound, body: `{"detail": "Plugin not found"}`, wantIs: ErrNotFound, wantDetail: "Plugin not found"},
+		{name: "unauthorized", statusCode: http.StatusForbidden, body: `{"detail": {"error": "Invalid Credentials"}}`, wantIs: ErrUnauthorized, wantDetail: "Invalid Credentials"},
+		{name: "plain body", statusCode: http.StatusInternalServerError, body: `Internal Server Error`, wantDetail: "Internal Server Error"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.statusCode)
+				io.WriteString(w, test.body)
+			})
+
+			_, err := client.GetPlugin(context.Background(), "my_plugin")
+
+			var networkError *NetworkError
+			if !errors.As(err, &networkError) {
+				t.Fatalf("GetPlugin() error = %v, want *NetworkError", err)
+			}
+			if networkError.StatusCode != test.statusCode {
+				t.Errorf("StatusCode = %d, want %d", networkError.StatusCode, test.statusCode)
+			}
+			if networkError.Detail != test.wantDetail {
+				t.Errorf("Detail = %q, want %q", networkError.Detail, test.wantDetail)
+			}
+			if test.wantIs != nil && !errors.Is(err, test.wantIs) {
+				t.Errorf("errors.Is(%v, %v) = false, want true", err, test.wantIs)
+			}
+		})
+	}
+}
+
+func TestClientSettings(t *testing.T) {
+	client := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/settings/":
+			if got := r.URL.Query().Get("search"); got != "lang" {
+				t.Errorf("search = %q, want %q", got, "lang")
+			}
+			writeJSON(t, w, http.StatusOK, map[string]any{
+				"settings": []Setting{{SettingID: "1", Name: "language", Value: map[string]any{"code": "en"}}},
+			})
+		case r.Method == http.MethodPut && r.URL.Path == "/settings/1":
+			var setting Setting
+			json.NewDecoder(r.Body).Decode(&setting)
+			setting.SettingID = "1"
+			writeJSON(t, w, http.StatusOK, map[string]any{"setting": setting})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	settings, err := client.GetSettings(context.Background(), "lang")
+	if err != nil {
+		t.Fatalf("GetSettings() error = %v", err)
+	}
+	if len(settings) != 1 || settings[0].Name != "language" {
+		t.Errorf("GetSettings() = %+v, want the language setting", settings)
+	}
+
+	updated, err := client.UpdateSetting(context.Background(), "1", Setting{Name: "language", Value: map[string]any{"code": "it"}})
+	if err != nil {
+		t.Fatalf("UpdateSetting() error = %v", err)
+	}
+	if updated.Value["code"] != "it" {
+		t.Errorf("UpdateSetting().Value = %v, want code it", updated.Value)
+	}
+}
+
+func TestClientLLMSettings(t *testing.T) {
+	client := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/llm/settings":
+			writeJSON(t, w, http.StatusOK, FactorySettings{
+				Settings:              []SchemaSetting{{Name: "LLMOpenAIConfig", Value: map[string]any{}}},
+				SelectedConfiguration: "LLMOpenAIConfig",
+			})
+		case r.Method == http.MethodPut && r.URL.Path == "/llm/settings/LLMOpenAIConfig":
+			var value map[string]any
+			json.NewDecoder(r.Body).Decode(&value)
+			writeJSON(t, w, http.StatusOK, SchemaSetting{Name: "LLMOpenAIConfig", Value: value})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	settings, err := client.GetLLMSettings(context.Background())
+	if err != nil {
+		t.Fatalf("GetLLMSettings() error = %v", err)
+	}
+	if settings.SelectedConfiguration != "LLMOpenAIConfig" {
+		t.Errorf("SelectedConfiguration = %q, want %q", settings.SelectedConfiguration, "LLMOpenAIConfig")
+	}
+
+	setting, err := client.UpdateLLMSetting(context.Background(), "LLMOpenAIConfig", map[string]any{"model_name": "gpt-4o"})
+	if err != nil {
+		t.Fatalf("UpdateLLMSetting() error = %v", err)
+	}
+	if setting.Value["model_name"] != "gpt-4o" {
+		t.Errorf("UpdateLLMSetting().Value = %v, want model_name gpt-4o", setting.Value)
+	}
+}
+
+func TestClientPlugins(t *testing.T) {
+	client := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/plugins":
+			writeJSON(t, w, http.StatusOK, map[string]any{
+				"filters":   map[string]any{"query": r.URL.Query().Get("query")},
+				"installed": []Plugin{{ID: "core_plugin", Name: "Core Plugin", Version: "0.0.1", Active: true}},
+				"registry":  []RegistryPlugin{},
+			})
+		case r.Method == http.MethodPost && r.URL.Path == "/plugins/upload/":
+			file, header, err := r.FormFile("file")
+			if err != nil {
+				t.Fatalf("FormFile() error = %v", err)
+			}
+			content, _ := io.ReadAll(file)
+			if header.Filename != "my_plugin.zip" || string(content) != "zip content" {
+				t.Errorf("uploaded %q with content %q", header.Filename, content)
+			}
+			writeJSON(t, w, http.StatusOK, UploadResult{Filename: header.Filename, Info: "Plugin is being installed asynchronously"})
+		case r.Method == http.MethodPut && r.URL.Path == "/plugins/toggle/my_plugin":
+			writeJSON(t, w, http.StatusOK, map[string]string{"info": "Plugin my_plugin toggled"})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	plugins, err := client.ListPlugins(context.Background(), "core")
+	if err != nil {
+		t.Fatalf("ListPlugins() error = %v", err)
+	}
+	if plugins.Filters.Query != "core" || len(plugins.Installed) != 1 || !plugins.Installed[0].Active {
+		t.Errorf("ListPlugins() = %+v, want the active core plugin", plugins)
+	}
+
+	result, err := client.UploadPlugin(context.Background(), "my_plugin.zip", strings.NewReader("zip content"))
+	if err != nil {
+		t.Fatalf("UploadPlugin() error = %v", err)
+	}
+	if result.Filename != "my_plugin.zip" {
+		t.Errorf("UploadPlugin().Filename = %q, want %q", result.Filename, "my_plugin.zip")
+	}
+
+	err = client.TogglePlugin(context.Background(), "my_plugin")
+	if err != nil {
+		t.Fatalf("TogglePlugin() error = %v", err)
+	}
+}
+
+func TestRegistryClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/plugins":
+			if r.URL.Query().Get("page") != "1" {
+				t.Errorf("unexpected plugins query %q", r.URL.RawQuery)
+			}
+			writeJSON(t, w, http.StatusOK, map[string]any{
+				"plugins": []RegistryPlugin{{Name: "Weather", Version: "1.0.0"}, {Name: "Pizza", Version: "0.2.0"}},
+			})
+		case r.Method == http.MethodPost && r.URL.Path == "/search":
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body["query"] != "weather" {
+				t.Errorf("search query = %q, want %q", body["query"], "weather")
+			}
+			writeJSON(t, w, http.StatusOK, []RegistryPlugin{{Name: "Weather", AuthorName: "Jane", Version: "1.0.0", URL: "https://github.com/jane/weather"}})
+		case r.Method == http.MethodPost && r.URL.Path == "/download":
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body["url"] != "https://github.com/jane/weather" {
+				writeJSON(t, w, http.StatusNotFound, map[string]string{"detail": "plugin not found"})
+				return
+			}
+			w.Header().Set("Content-Type", "application/zip")
+			_, _ = w.Write([]byte("zip content"))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	registry, err := NewRegistryClient(server.Client(), server.URL)
+	if err != nil {
+		t.Fatalf("NewRegistryClient() error = %v", err)
+	}
+
+	plugins, err := registry.Search(context.Background(), "")
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(plugins) != 2 {
+		t.Errorf("Search(\"\") returned %d plugins, want 2", len(plugins))
+	}
+
+	plugins, err = registry.Search(context.Background(), "weather")
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(plugins) != 1 || plugins[0].AuthorName != "Jane" {
+		t.Errorf("Search(\"weather\") = %+v, want the Weather plugin", plugins)
+	}
+
+	archive, err := registry.Download(context.Background(), plugins[0].URL)
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if string(archive) != "zip content" {
+		t.Errorf("Download() = %q, want %q", archive, "zip content")
+	}
+
+	_, err = registry.Download(context.Background(), "https://github.com/unknown/plugin")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Download() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestClientMemory(t *testing.T) {
+	client := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/memory/recall":
+			if r.URL.Query().Get("text") != "hello" || r.URL.Query().Get("k") != "3" {
+				t.Errorf("unexpected recall query %q", r.URL.RawQuery)
+			}
+			writeJSON(t, w, http.StatusOK, map[string]any{
+				"query": map[string]any{"text": "hello"},
+				"vectors": map[string]any{
+					"embedder": "FakeEmbedder",
+					"collections": map[string]any{
+						"declarative": []map[string]any{{"id": "1", "page_content": "hello world", "score": 0.9, "metadata": map[string]any{"source": "hello.txt"}}},
+					},
+				},
+			})
+		case r.Method == http.MethodGet && r.URL.Path == "/memory/collections/declarative/points":
+			if r.URL.Query().Get("offset") == "" {
+				writeJSON(t, w, http.StatusOK, map[string]any{"points": []map[string]any{{"id": "1"}}, "next_offset": 2})
+				return
+			}
+			writeJSON(t, w, http.StatusOK, map[string]any{"points": []map[string]any{{"id": "2"}}, "next_offset": nil})
+		case r.Method == http.MethodDelete && r.URL.Path == "/memory/collections/declarative/points":
+			var metadata map[string]any
+			json.NewDecoder(r.Body).Decode(&metadata)
+			if !reflect.DeepEqual(metadata, map[string]any{"source": "hello.txt"}) {
+				t.Errorf("delete metadata = %v", metadata)
+			}
+			writeJSON(t, w, http.StatusOK, map[string]any{"deleted": true})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	recall, err := client.Recall(context.Background(), "hello", 3)
+	if err != nil {
+		t.Fatalf("Recall() error = %v", err)
+	}
+	memories := recall.Vectors.Collections["declarative"]
+	if len(memories) != 1 || memories[0].Score != 0.9 || memories[0].Metadata["source"] != "hello.txt" {
+		t.Errorf("Recall() declarative memories = %+v", memories)
+	}
+
+	var ids []string
+	var offset Offset
+	for {
+		page, err := client.GetPoints(context.Background(), "declarative", 1, offset)
+		if err != nil {
+			t.Fatalf("GetPoints() error = %v", err)
+		}
+		for _, point := range page.Points {
+			ids = append(ids, point.ID)
+		}
+		if page.NextOffset == "" {
+			break
+		}
+		offset = page.NextOffset
+	}
+	if !reflect.DeepEqual(ids, []string{"1", "2"}) {
+		t.Errorf("paged point ids = %v, want [1 2]", ids)
+	}
+
+	err = client.DeletePointsByMetadata(context.Background(), "declarative", map[string]any{"source": "hello.txt"})
+	if err != nil {
+		t.Fatalf("DeletePointsByMetadata() error = %v", err)
+	}
+}
+
+func TestClientUploadFile(t *testing.T) {
+	client := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/rabbithole/" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		err := r.ParseMultipartForm(1 << 20)
+		if err != nil {
+			t.Fatalf("ParseMultipartForm() error = %v", err)
+		}
+		if r.FormValue("chunk_size") != "512" || r.FormValue("chunk_overlap") != "64" {
+			t.Errorf("chunk_size = %q, chunk_overlap = %q", r.FormValue("chunk_size"), r.FormValue("chunk_overlap"))
+		}
+		if r.FormValue("metadata") != `{"team":"docs"}` {
+			t.Errorf("metadata = %q", r.FormValue("metadata"))
+		}
+
+		writeJSON(t, w, http.StatusOK, UploadResult{Filename: "notes.md", Info: "File is being ingested asynchronously"})
+	})
+
+	result, err := client.UploadFile(context.Background(), "notes.md", strings.NewReader("# notes"), UploadOptions{
+		ChunkSize:    512,
+		ChunkOverlap: 64,
+		Metadata:     map[string]any{"team": "docs"},
+	})
+	if err != nil {
+		t.Fatalf("UploadFile() error = %v", err)
+	}
+	if result.Filename != "notes.md" {
+		t.Errorf("UploadFile().Filename = %q, want %q", result.Filename, "notes.md")
+	}
+}
+
+func TestClientChat(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	client := newTestClient(t, ClientConfig{APIKey: "secret", UserID: "alice"}, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ws/alice" || r.URL.Query().Get("token") != "secret" {
+			t.Errorf("unexpected chat URL %s", r.URL)
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Fatalf("Upgrade() error = %v", err)
+		}
+		defer conn.Close()
+
+		var message map[string]string
+		err = conn.ReadJSON(&message)
+		if err != nil {
+			t.Fatalf("ReadJSON() error = %v", err)
+		}
+
+		conn.WriteJSON(ChatMessage{Type: MessageTypeChatToken, Content: "Meow "})
+		conn.WriteJSON(map[string]any{
+			"type":    MessageTypeChat,
+			"content": "Meow " + message["text"],
+			"why": map[string]any{
+				"input":              message["text"],
+				"intermediate_steps": []any{[]any{[]any{"get_the_time", ""}, "12:00"}},
+				"memory":             map[string]any{"episodic": []any{map[string]any{"page_content": "hi", "score": 0.8}}},
+			},
+		})
+	})
+
+	connection, err := client.DialChat(context.Background())
+	if err != nil {
+		t.Fatalf("DialChat() error = %v", err)
+	}
+	defer connection.Close()
+
+	err = connection.Send("hello")
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	token, err := connection.Receive()
+	if err != nil || token.Type != MessageTypeChatToken || token.Message() != "Meow " {
+		t.Fatalf("Receive() = %+v, %v, want the chat token", token, err)
+	}
+
+	reply, err := connection.Receive()
+	if err != nil || reply.Type != MessageTypeChat || reply.Message() != "Meow hello" {
+		t.Fatalf("Receive() = %+v, %v, want the chat reply", reply, err)
+	}
+
+	why, err := reply.ParseWhy()
+	if err != nil {
+		t.Fatalf("ParseWhy() error = %v", err)
+	}
+	if tools := why.ToolsUsed(); !reflect.DeepEqual(tools, []string{"get_the_time"}) {
+		t.Errorf("ToolsUsed() = %v, want [get_the_time]", tools)
+	}
+	if len(why.Memory["episodic"]) != 1 || why.Memory["episodic"][0].Score != 0.8 {
+		t.Errorf("Memory = %+v, want one episodic memory", why.Memory)
+	}
+}
+
+func TestClientSendMessage(t *testing.T) {
+	client := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/message" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		var message map[string]string
+		json.NewDecoder(r.Body).Decode(&message)
+		if message["text"] == "fail" {
+			writeJSON(t, w, http.StatusOK, ChatMessage{Type: MessageTypeError, Name: "ValueError", Description: "boom"})
+			return
+		}
+
+		writeJSON(t, w, http.StatusOK, ChatMessage{Type: MessageTypeChat, Content: "Meow " + message["text"]})
+	})
+
+	reply, err := client.SendMessage(context.Background(), "hello")
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	if reply.Message() != "Meow hello" {
+		t.Errorf("SendMessage().Message() = %q, want %q", reply.Message(), "Meow hello")
+	}
+
+	_, err = client.SendMessage(context.Background(), "fail")
+	if err == nil {
+		t.Errorf("SendMessage() error = nil, want the cat error")
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+var (
+	ErrNilHTTPClient = fmt.Errorf("nil HTTP client provided")
+	ErrUnauthorized  = errors.New("unauthorized, check the cat API key")
+	ErrNotFound      = errors.New("not found")
+)
+
+// ErrInvalidBaseURL is returned when the cat API URL is not a valid http(s) URL.
+func ErrInvalidBaseURL(baseURL string) error {
+	return fmt.Errorf("invalid cat API URL %q", baseURL)
+}
+
+// NetworkError is returned when the cat API answers with a non successful status code.
+//
+// It matches ErrUnauthorized and ErrNotFound with errors.Is, depending on the status code.
+type NetworkError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	// Detail is the error message returned by the cat, if any.
+	Detail string
+}
+
+// ErrNetwork returns a *NetworkError for the specified status code.
+func ErrNetwork(statusCode int) error {
+	return &NetworkError{StatusCode: statusCode}
+}
+
+func (err *NetworkError) Error() string {
+	message := fmt.Sprintf("request failed with status code %d", err.StatusCode)
+	if err.Method != "" {
+		message = fmt.Sprintf("%s %s %s", err.Method, err.Path, message)
+	}
+	if err.Detail != "" {
+		message = fmt.Sprintf("%s: %s", message, err.Detail)
+	}
+
+	return message
+}
+
+func (err *NetworkError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return err.StatusCode == http.StatusUnauthorized || err.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return err.StatusCode == http.StatusNotFound
+	default:
+		return false
+	}
+}
+
+// newNetworkError builds the *NetworkError from the response, reading the FastAPI error detail.
+func newNetworkError(req *http.Request, resp *http.Response) error {
+	networkError := &NetworkError{
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		StatusCode: resp.StatusCode,
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return networkError
+	}
+
+	var errorBody struct {
+		Detail json.RawMessage `json:"detail"`
+	}
+	err = json.Unmarshal(body, &errorBody)
+	if err != nil || len(errorBody.Detail) == 0 {
+		networkError.Detail = string(body)
+		return networkError
+	}
+
+	var detailMessage string
+	if json.Unmarshal(errorBody.Detail, &detailMessage) == nil {
+		networkError.Detail = detailMessage
+		return networkError
+	}
+
+	var detailObject struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(errorBody.Detail, &detailObject) == nil && detailObject.Error != "" {
+		networkError.Detail = detailObject.Error
+		return networkError
+	}
+
+	networkError.Detail = string(errorBody.Detail)
+	return networkError
+}
@@ -0,0 +1,101 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cat
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// SchemaSetting represents a configuration (of a factory or plugin) with its JSON schema.
+type SchemaSetting struct {
+	Name   string         `json:"name"`
+	Value  map[string]any `json:"value"`
+	Schema map[string]any `json:"schema,omitempty"`
+}
+
+// FactorySettings represents all the available configurations of a cat factory.
+type FactorySettings struct {
+	Settings              []SchemaSetting `json:"settings"`
+	SelectedConfiguration string          `json:"selected_configuration"`
+}
+
+// GetLLMSettings returns the available LLM configurations and the selected one.
+func (client *Client) GetLLMSettings(ctx context.Context) (*FactorySettings, error) {
+	return client.getFactorySettings(ctx, "/llm/settings")
+}
+
+// GetLLMSetting returns the LLM configuration with the specified name.
+func (client *Client) GetLLMSetting(ctx context.Context, name string) (*SchemaSetting, error) {
+	return client.getFactorySetting(ctx, "/llm/settings", name)
+}
+
+// UpdateLLMSetting selects and updates the LLM configuration with the specified name.
+func (client *Client) UpdateLLMSetting(ctx context.Context, name string, value map[string]any) (*SchemaSetting, error) {
+	return client.updateFactorySetting(ctx, "/llm/settings", name, value)
+}
+
+// GetEmbedderSettings returns the available embedder configurations and the selected one.
+func (client *Client) GetEmbedderSettings(ctx context.Context) (*FactorySettings, error) {
+	return client.getFactorySettings(ctx, "/embedder/settings")
+}
+
+// GetEmbedderSetting returns the embedder configuration with the specified name.
+func (client *Client) GetEmbedderSetting(ctx context.Context, name string) (*SchemaSetting, error) {
+	return client.getFactorySetting(ctx, "/embedder/settings", name)
+}
+
+// UpdateEmbedderSetting selects and updates the embedder configuration with the specified name.
+func (client *Client) UpdateEmbedderSetting(ctx context.Context, name string, value map[string]any) (*SchemaSetting, error) {
+	return client.updateFactorySetting(ctx, "/embedder/settings", name, value)
+}
+
+func (client *Client) getFactorySettings(ctx context.Context, path string) (*FactorySettings, error) {
+	settings := new(FactorySettings)
+	err := client.doJSON(ctx, http.MethodGet, path, nil, nil, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func (client *Client) getFactorySetting(ctx context.Context, path string, name string) (*SchemaSetting, error) {
+	setting := new(SchemaSetting)
+	err := client.doJSON(ctx, http.MethodGet, path+"/"+url.PathEscape(name), nil, nil, setting)
+	if err != nil {
+		return nil, err
+	}
+
+	return setting, nil
+}
+
+func (client *Client) updateFactorySetting(ctx context.Context, path string, name string, value map[string]any) (*SchemaSetting, error) {
+	if value == nil {
+		value = map[string]any{}
+	}
+
+	setting := new(SchemaSetting)
+	err := client.doJSON(ctx, http.MethodPut, path+"/"+url.PathEscape(name), nil, value, setting)
+	if err != nil {
+		return nil, err
+	}
+
+	return setting, nil
+}
@@ -0,0 +1,212 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Collection represents a vector memory collection of the cat.
+type Collection struct {
+	Name         string `json:"name"`
+	VectorsCount int    `json:"vectors_count"`
+}
+
+// MemoryPoint represents a point stored in a vector memory collection.
+type MemoryPoint struct {
+	ID      string        `json:"id"`
+	Payload MemoryPayload `json:"payload"`
+	Vector  []float64     `json:"vector,omitempty"`
+}
+
+// MemoryPayload represents the content of a memory point.
+type MemoryPayload struct {
+	PageContent string         `json:"page_content"`
+	Metadata    map[string]any `json:"metadata"`
+}
+
+// Offset is the offset of a memory points page, empty when there are no more pages.
+type Offset string
+
+func (offset *Offset) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*offset = ""
+		return nil
+	}
+
+	var text string
+	if json.Unmarshal(data, &text) == nil {
+		*offset = Offset(text)
+		return nil
+	}
+
+	var number json.Number
+	err := json.Unmarshal(data, &number)
+	if err != nil {
+		return err
+	}
+
+	*offset = Offset(number.String())
+	return nil
+}
+
+// MemoryPointsPage represents a page of memory points.
+type MemoryPointsPage struct {
+	Points     []MemoryPoint `json:"points"`
+	NextOffset Offset        `json:"next_offset"`
+}
+
+// RecalledMemory represents a memory recalled by similarity search.
+type RecalledMemory struct {
+	ID          string         `json:"id"`
+	PageContent string         `json:"page_content"`
+	Metadata    map[string]any `json:"metadata"`
+	Score       float64        `json:"score"`
+	Vector      []float64      `json:"vector,omitempty"`
+}
+
+// RecallResult represents the response of a memory recall.
+type RecallResult struct {
+	Query struct {
+		Text   string    `json:"text"`
+		Vector []float64 `json:"vector,omitempty"`
+	} `json:"query"`
+	Vectors struct {
+		Embedder    string                      `json:"embedder"`
+		Collections map[string][]RecalledMemory `json:"collections"`
+	} `json:"vectors"`
+}
+
+// ConversationMessage represents a message of the conversation history.
+type ConversationMessage struct {
+	Who     string         `json:"who"`
+	Message string         `json:"message"`
+	Why     map[string]any `json:"why,omitempty"`
+	When    float64        `json:"when"`
+}
+
+// GetCollections returns the vector memory collections.
+func (client *Client) GetCollections(ctx context.Context) ([]Collection, error) {
+	var response struct {
+		Collections []Collection `json:"collections"`
+	}
+	err := client.doJSON(ctx, http.MethodGet, "/memory/collections", nil, nil, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Collections, nil
+}
+
+// WipeCollections deletes and recreates all the vector memory collections.
+func (client *Client) WipeCollections(ctx context.Context) error {
+	return client.doJSON(ctx, http.MethodDelete, "/memory/collections", nil, nil, nil)
+}
+
+// WipeCollection deletes and recreates the specified vector memory collection.
+func (client *Client) WipeCollection(ctx context.Context, collection string) error {
+	return client.doJSON(ctx, http.MethodDelete, "/memory/collections/"+url.PathEscape(collection), nil, nil, nil)
+}
+
+// Recall returns the k memories of each collection most similar to the text.
+func (client *Client) Recall(ctx context.Context, text string, k int) (*RecallResult, error) {
+	query := url.Values{}
+	query.Set("text", text)
+	if k > 0 {
+		query.Set("k", strconv.Itoa(k))
+	}
+
+	result := new(RecallResult)
+	err := client.doJSON(ctx, http.MethodGet, "/memory/recall", query, nil, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetPoints returns a page of points of the collection, starting from offset (empty for the first page).
+func (client *Client) GetPoints(ctx context.Context, collection string, limit int, offset Offset) (*MemoryPointsPage, error) {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	if offset != "" {
+		query.Set("offset", string(offset))
+	}
+
+	page := new(MemoryPointsPage)
+	err := client.doJSON(ctx, http.MethodGet, "/memory/collections/"+url.PathEscape(collection)+"/points", query, nil, page)
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// CreatePoint stores a new point in the collection, embedding its content.
+func (client *Client) CreatePoint(ctx context.Context, collection string, content string, metadata map[string]any) (*MemoryPoint, error) {
+	body := map[string]any{
+		"content":  content,
+		"metadata": metadata,
+	}
+
+	point := new(MemoryPoint)
+	err := client.doJSON(ctx, http.MethodPost, "/memory/collections/"+url.PathEscape(collection)+"/points", nil, body, point)
+	if err != nil {
+		return nil, err
+	}
+
+	return point, nil
+}
+
+// DeletePoint deletes the point with the specified id from the collection.
+func (client *Client) DeletePoint(ctx context.Context, collection string, pointID string) error {
+	path := "/memory/collections/" + url.PathEscape(collection) + "/points/" + url.PathEscape(pointID)
+	return client.doJSON(ctx, http.MethodDelete, path, nil, nil, nil)
+}
+
+// DeletePointsByMetadata deletes the points of the collection matching all the metadata.
+func (client *Client) DeletePointsByMetadata(ctx context.Context, collection string, metadata map[string]any) error {
+	path := "/memory/collections/" + url.PathEscape(collection) + "/points"
+	return client.doJSON(ctx, http.MethodDelete, path, nil, metadata, nil)
+}
+
+// GetConversationHistory returns the conversation history of the user.
+func (client *Client) GetConversationHistory(ctx context.Context) ([]ConversationMessage, error) {
+	var response struct {
+		History []ConversationMessage `json:"history"`
+	}
+	err := client.doJSON(ctx, http.MethodGet, "/memory/conversation_history", nil, nil, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.History, nil
+}
+
+// WipeConversationHistory deletes the conversation history of the user.
+func (client *Client) WipeConversationHistory(ctx context.Context) error {
+	return client.doJSON(ctx, http.MethodDelete, "/memory/conversation_history", nil, nil, nil)
+}
@@ -0,0 +1,44 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cat
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// ErrChat is returned when the cat replies with an error message.
+func ErrChat(name string, description string) error {
+	return fmt.Errorf("the cat replied with an error: %s: %s", name, description)
+}
+
+// SendMessage sends a message to the cat through the HTTP message endpoint, waiting for the whole reply.
+func (client *Client) SendMessage(ctx context.Context, text string) (*ChatMessage, error) {
+	reply := new(ChatMessage)
+	err := client.doJSON(ctx, http.MethodPost, "/message", nil, map[string]string{"text": text}, reply)
+	if err != nil {
+		return nil, err
+	}
+
+	if reply.Type == MessageTypeError {
+		return reply, ErrChat(reply.Name, reply.Description)
+	}
+
+	return reply, nil
+}
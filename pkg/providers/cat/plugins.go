@@ -0,0 +1,188 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cat
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Plugin represents a plugin installed in the cat.
+type Plugin struct {
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	AuthorName  string           `json:"author_name"`
+	AuthorURL   string           `json:"author_url"`
+	PluginURL   string           `json:"plugin_url"`
+	Tags        string           `json:"tags"`
+	Thumb       string           `json:"thumb"`
+	Version     string           `json:"version"`
+	Active      bool             `json:"active"`
+	Upgrade     string           `json:"upgrade,omitempty"`
+	Hooks       []PluginHook     `json:"hooks,omitempty"`
+	Tools       []PluginTool     `json:"tools,omitempty"`
+	Forms       []PluginForm     `json:"forms,omitempty"`
+	Endpoints   []PluginEndpoint `json:"endpoints,omitempty"`
+}
+
+// PluginHook represents a hook defined by a plugin.
+type PluginHook struct {
+	Name     string  `json:"name"`
+	Priority float64 `json:"priority"`
+}
+
+// PluginTool represents a tool defined by a plugin.
+type PluginTool struct {
+	Name string `json:"name"`
+}
+
+// PluginForm represents a form defined by a plugin.
+type PluginForm struct {
+	Name string `json:"name"`
+}
+
+// PluginEndpoint represents a custom endpoint defined by a plugin.
+type PluginEndpoint struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags,omitempty"`
+}
+
+// RegistryPlugin represents a plugin available in the plugins registry.
+type RegistryPlugin struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	AuthorName  string `json:"author_name"`
+	AuthorURL   string `json:"author_url"`
+	PluginURL   string `json:"plugin_url"`
+	Tags        string `json:"tags"`
+	Thumb       string `json:"thumb"`
+	Version     string `json:"version"`
+	URL         string `json:"url"`
+}
+
+// PluginList represents the installed plugins and the matching registry plugins.
+type PluginList struct {
+	Filters struct {
+		Query string `json:"query"`
+	} `json:"filters"`
+	Installed []Plugin         `json:"installed"`
+	Registry  []RegistryPlugin `json:"registry"`
+}
+
+// ListPlugins returns the installed plugins and the registry plugins, optionally filtered by query.
+func (client *Client) ListPlugins(ctx context.Context, query string) (*PluginList, error) {
+	values := url.Values{}
+	if query != "" {
+		values.Set("query", query)
+	}
+
+	plugins := new(PluginList)
+	err := client.doJSON(ctx, http.MethodGet, "/plugins", values, nil, plugins)
+	if err != nil {
+		return nil, err
+	}
+
+	return plugins, nil
+}
+
+// GetPlugin returns the installed plugin with the specified id.
+func (client *Client) GetPlugin(ctx context.Context, pluginID string) (*Plugin, error) {
+	var response struct {
+		Data Plugin `json:"data"`
+	}
+	err := client.doJSON(ctx, http.MethodGet, "/plugins/"+url.PathEscape(pluginID), nil, nil, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.Data, nil
+}
+
+// UploadPlugin installs the plugin from a zip (or tar) archive.
+func (client *Client) UploadPlugin(ctx context.Context, fileName string, archive io.Reader) (*UploadResult, error) {
+	result := new(UploadResult)
+	err := client.doMultipart(ctx, "/plugins/upload/", "file", fileName, archive, nil, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// InstallPluginFromRegistry installs the plugin with the specified registry URL.
+func (client *Client) InstallPluginFromRegistry(ctx context.Context, pluginURL string) (*UploadResult, error) {
+	result := new(UploadResult)
+	err := client.doJSON(ctx, http.MethodPost, "/plugins/upload/registry", nil, map[string]string{"url": pluginURL}, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// TogglePlugin activates or deactivates the plugin with the specified id.
+func (client *Client) TogglePlugin(ctx context.Context, pluginID string) error {
+	return client.doJSON(ctx, http.MethodPut, "/plugins/toggle/"+url.PathEscape(pluginID), nil, nil, nil)
+}
+
+// DeletePlugin uninstalls the plugin with the specified id.
+func (client *Client) DeletePlugin(ctx context.Context, pluginID string) error {
+	return client.doJSON(ctx, http.MethodDelete, "/plugins/"+url.PathEscape(pluginID), nil, nil, nil)
+}
+
+// GetPluginsSettings returns the settings of all the installed plugins.
+func (client *Client) GetPluginsSettings(ctx context.Context) ([]SchemaSetting, error) {
+	var response struct {
+		Settings []SchemaSetting `json:"settings"`
+	}
+	err := client.doJSON(ctx, http.MethodGet, "/plugins/settings", nil, nil, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Settings, nil
+}
+
+// GetPluginSettings returns the settings of the plugin with the specified id, with their JSON schema.
+func (client *Client) GetPluginSettings(ctx context.Context, pluginID string) (*SchemaSetting, error) {
+	settings := new(SchemaSetting)
+	err := client.doJSON(ctx, http.MethodGet, "/plugins/settings/"+url.PathEscape(pluginID), nil, nil, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// UpdatePluginSettings updates the settings of the plugin with the specified id.
+func (client *Client) UpdatePluginSettings(ctx context.Context, pluginID string, value map[string]any) (*SchemaSetting, error) {
+	if value == nil {
+		value = map[string]any{}
+	}
+
+	settings := new(SchemaSetting)
+	err := client.doJSON(ctx, http.MethodPut, "/plugins/settings/"+url.PathEscape(pluginID), nil, value, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
@@ -0,0 +1,118 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cat
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// UploadOptions represents the optional parameters of the rabbit hole uploads.
+type UploadOptions struct {
+	// ChunkSize is the size of the chunks the document is split into (0 uses the cat default).
+	ChunkSize int
+	// ChunkOverlap is the overlap between consecutive chunks (0 uses the cat default).
+	ChunkOverlap int
+	// Metadata is stored along with each chunk of the document.
+	Metadata map[string]any
+}
+
+// UploadResult represents the response of a rabbit hole or plugin upload.
+type UploadResult struct {
+	Filename    string `json:"filename,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Info        string `json:"info"`
+}
+
+// UploadFile sends the file to the rabbit hole, which splits it into chunks stored in the declarative memory.
+func (client *Client) UploadFile(ctx context.Context, fileName string, file io.Reader, options UploadOptions) (*UploadResult, error) {
+	fields := map[string]string{}
+	if options.ChunkSize > 0 {
+		fields["chunk_size"] = strconv.Itoa(options.ChunkSize)
+	}
+	if options.ChunkOverlap > 0 {
+		fields["chunk_overlap"] = strconv.Itoa(options.ChunkOverlap)
+	}
+	if len(options.Metadata) > 0 {
+		metadata, err := json.Marshal(options.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		fields["metadata"] = string(metadata)
+	}
+
+	result := new(UploadResult)
+	err := client.doMultipart(ctx, "/rabbithole/", "file", fileName, file, fields, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// UploadURL makes the rabbit hole download and ingest the web page at the specified URL.
+func (client *Client) UploadURL(ctx context.Context, pageURL string, options UploadOptions) (*UploadResult, error) {
+	body := map[string]any{
+		"url": pageURL,
+	}
+	if options.ChunkSize > 0 {
+		body["chunk_size"] = options.ChunkSize
+	}
+	if options.ChunkOverlap > 0 {
+		body["chunk_overlap"] = options.ChunkOverlap
+	}
+	if len(options.Metadata) > 0 {
+		body["metadata"] = options.Metadata
+	}
+
+	result := new(UploadResult)
+	err := client.doJSON(ctx, http.MethodPost, "/rabbithole/web", nil, body, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// UploadMemory sends a declarative memory JSON export to the rabbit hole.
+func (client *Client) UploadMemory(ctx context.Context, fileName string, memory io.Reader) (*UploadResult, error) {
+	result := new(UploadResult)
+	err := client.doMultipart(ctx, "/rabbithole/memory", "file", fileName, memory, nil, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// AllowedMimeTypes returns the mime types accepted by the rabbit hole.
+func (client *Client) AllowedMimeTypes(ctx context.Context) ([]string, error) {
+	var response struct {
+		Allowed []string `json:"allowed"`
+	}
+	err := client.doJSON(ctx, http.MethodGet, "/rabbithole/allowed-mimetypes", nil, nil, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Allowed, nil
+}
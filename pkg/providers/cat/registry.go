@@ -0,0 +1,79 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cat
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// DefaultRegistryURL is the URL of the public Cheshire Cat plugin registry.
+const DefaultRegistryURL = "https://registry.cheshirecat.ai"
+
+// RegistryClient is a client for the Cheshire Cat plugin registry,
+// used when the registry is queried directly instead of through the cat.
+type RegistryClient struct {
+	client *Client
+}
+
+// NewRegistryClient creates a new RegistryClient for the registry at registryURL.
+func NewRegistryClient(httpClient httpClient, registryURL string) (*RegistryClient, error) {
+	client, err := NewClient(httpClient, ClientConfig{BaseURL: registryURL})
+	if err != nil {
+		return nil, err
+	}
+
+	return &RegistryClient{client: client}, nil
+}
+
+// Search returns the registry plugins matching the query, or all of them if the query is empty.
+func (registry *RegistryClient) Search(ctx context.Context, query string) ([]RegistryPlugin, error) {
+	if query == "" {
+		var response struct {
+			Plugins []RegistryPlugin `json:"plugins"`
+		}
+		values := url.Values{"page": {"1"}, "page_size": {"1000"}}
+		err := registry.client.doJSON(ctx, http.MethodGet, "/plugins", values, nil, &response)
+		if err != nil {
+			return nil, err
+		}
+
+		return response.Plugins, nil
+	}
+
+	var plugins []RegistryPlugin
+	err := registry.client.doJSON(ctx, http.MethodPost, "/search", nil, map[string]string{"query": query}, &plugins)
+	if err != nil {
+		return nil, err
+	}
+
+	return plugins, nil
+}
+
+// Download returns the zip archive of the registry plugin with the specified URL.
+func (registry *RegistryClient) Download(ctx context.Context, pluginURL string) ([]byte, error) {
+	var archive bytes.Buffer
+	err := registry.client.doJSON(ctx, http.MethodPost, "/download", nil, map[string]string{"url": pluginURL}, &archive)
+	if err != nil {
+		return nil, err
+	}
+
+	return archive.Bytes(), nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cat
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Setting represents a setting stored in the cat database.
+type Setting struct {
+	SettingID string         `json:"setting_id,omitempty"`
+	Name      string         `json:"name"`
+	Value     map[string]any `json:"value"`
+	Category  string         `json:"category,omitempty"`
+	UpdatedAt int64          `json:"updated_at,omitempty"`
+}
+
+// GetSettings returns the settings stored in the cat, optionally filtered by name.
+func (client *Client) GetSettings(ctx context.Context, search string) ([]Setting, error) {
+	query := url.Values{}
+	if search != "" {
+		query.Set("search", search)
+	}
+
+	var response struct {
+		Settings []Setting `json:"settings"`
+	}
+	err := client.doJSON(ctx, http.MethodGet, "/settings/", query, nil, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Settings, nil
+}
+
+// GetSetting returns the setting with the specified id.
+func (client *Client) GetSetting(ctx context.Context, settingID string) (*Setting, error) {
+	var response struct {
+		Setting Setting `json:"setting"`
+	}
+	err := client.doJSON(ctx, http.MethodGet, "/settings/"+url.PathEscape(settingID), nil, nil, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.Setting, nil
+}
+
+// CreateSetting stores a new setting in the cat.
+func (client *Client) CreateSetting(ctx context.Context, setting Setting) (*Setting, error) {
+	var response struct {
+		Setting Setting `json:"setting"`
+	}
+	err := client.doJSON(ctx, http.MethodPost, "/settings/", nil, setting, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.Setting, nil
+}
+
+// UpdateSetting updates the setting with the specified id.
+func (client *Client) UpdateSetting(ctx context.Context, settingID string, setting Setting) (*Setting, error) {
+	var response struct {
+		Setting Setting `json:"setting"`
+	}
+	err := client.doJSON(ctx, http.MethodPut, "/settings/"+url.PathEscape(settingID), nil, setting, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.Setting, nil
+}
+
+// DeleteSetting deletes the setting with the specified id.
+func (client *Client) DeleteSetting(ctx context.Context, settingID string) error {
+	return client.doJSON(ctx, http.MethodDelete, "/settings/"+url.PathEscape(settingID), nil, nil, nil)
+}
@@ -0,0 +1,40 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cat
+
+import (
+	"context"
+	"net/http"
+)
+
+// Status represents the response of the cat root endpoint.
+type Status struct {
+	Status  string `json:"status"`
+	Version string `json:"version"`
+}
+
+// Status returns the status and version of the cat.
+func (client *Client) Status(ctx context.Context) (*Status, error) {
+	status := new(Status)
+	err := client.doJSON(ctx, http.MethodGet, "/", nil, nil, status)
+	if err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
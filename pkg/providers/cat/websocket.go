@@ -0,0 +1,172 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cat
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/websocket"
+)
+
+// Types of the messages sent by the cat.
+const (
+	MessageTypeChat         = "chat"
+	MessageTypeChatToken    = "chat_token"
+	MessageTypeNotification = "notification"
+	MessageTypeError        = "error"
+)
+
+// ChatMessage represents a message sent by the cat, over the WebSocket or as response of the message endpoint.
+type ChatMessage struct {
+	Type    string `json:"type"`
+	Content string `json:"content,omitempty"`
+	// Text is the message content sent by older cats.
+	Text   string `json:"text,omitempty"`
+	UserID string `json:"user_id,omitempty"`
+	// Why contains the raw reasoning details of the reply, see ParseWhy.
+	Why json.RawMessage `json:"why,omitempty"`
+	// Name and Description are set for error messages.
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Message returns the content of the message.
+func (message *ChatMessage) Message() string {
+	if message.Content != "" {
+		return message.Content
+	}
+
+	return message.Text
+}
+
+// ParseWhy returns the reasoning details of the reply, nil if missing.
+func (message *ChatMessage) ParseWhy() (*MessageWhy, error) {
+	if len(message.Why) == 0 || string(message.Why) == "null" {
+		return nil, nil
+	}
+
+	why := new(MessageWhy)
+	err := json.Unmarshal(message.Why, why)
+	if err != nil {
+		return nil, err
+	}
+
+	return why, nil
+}
+
+// MessageWhy represents the reasoning details of a cat reply.
+type MessageWhy struct {
+	Input string `json:"input"`
+	// IntermediateSteps are the tool calls in the [[tool, tool input], tool output] format.
+	IntermediateSteps []json.RawMessage `json:"intermediate_steps"`
+	// Memory contains the recalled memories grouped by collection.
+	Memory map[string][]RecalledMemory `json:"memory"`
+}
+
+// ToolsUsed returns the names of the tools used to produce the reply.
+func (why *MessageWhy) ToolsUsed() []string {
+	var tools []string
+	for _, step := range why.IntermediateSteps {
+		var stepParts []json.RawMessage
+		if json.Unmarshal(step, &stepParts) != nil || len(stepParts) == 0 {
+			continue
+		}
+
+		var toolCall []any
+		if json.Unmarshal(stepParts[0], &toolCall) != nil || len(toolCall) == 0 {
+			continue
+		}
+
+		if toolName, ok := toolCall[0].(string); ok {
+			tools = append(tools, toolName)
+		}
+	}
+
+	return tools
+}
+
+// ChatConnection is a WebSocket connection to the cat chat.
+type ChatConnection struct {
+	conn *websocket.Conn
+}
+
+// ChatURL returns the URL of the cat chat WebSocket for the client user.
+func (client *Client) ChatURL() *url.URL {
+	chatURL := client.BaseURL()
+	if chatURL.Scheme == "https" {
+		chatURL.Scheme = "wss"
+	} else {
+		chatURL.Scheme = "ws"
+	}
+
+	chatURL = chatURL.JoinPath("ws")
+	if client.userID != "" {
+		chatURL = chatURL.JoinPath(client.userID)
+	}
+
+	if client.apiKey != "" {
+		query := chatURL.Query()
+		query.Set("token", client.apiKey)
+		chatURL.RawQuery = query.Encode()
+	}
+
+	return chatURL
+}
+
+// DialChat opens a WebSocket connection to the cat chat.
+func (client *Client) DialChat(ctx context.Context) (*ChatConnection, error) {
+	header := http.Header{}
+	if client.apiKey != "" {
+		header.Set("Authorization", "Bearer "+client.apiKey)
+	}
+
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, client.ChatURL().String(), header)
+	if resp != nil && resp.StatusCode != http.StatusSwitchingProtocols {
+		defer resp.Body.Close()
+		return nil, ErrNetwork(resp.StatusCode)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChatConnection{conn: conn}, nil
+}
+
+// Send sends a user message to the cat.
+func (connection *ChatConnection) Send(text string) error {
+	return connection.conn.WriteJSON(map[string]string{"text": text})
+}
+
+// Receive waits for the next message sent by the cat.
+func (connection *ChatConnection) Receive() (*ChatMessage, error) {
+	message := new(ChatMessage)
+	err := connection.conn.ReadJSON(message)
+	if err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+// Close closes the WebSocket connection.
+func (connection *ChatConnection) Close() error {
+	return connection.conn.Close()
+}
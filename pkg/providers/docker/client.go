@@ -19,11 +19,13 @@ along with this program. If not, see <http://www.gnu.org/licenses/>.
 package docker
 
 import (
-	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"strconv"
 
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/image"
@@ -32,7 +34,26 @@ import (
 	"github.com/docker/go-connections/nat"
 )
 
-type onPullProgressFunc func(reader io.Reader)
+// Paths of the cat folders inside the container.
+const (
+	CatPluginsPath = "/app/cat/plugins"
+	CatDataPath    = "/app/cat/data"
+	CatStaticPath  = "/app/cat/static"
+)
+
+// catPort is the port the cat listens on inside the container.
+const catPort = nat.Port("80/tcp")
+
+// PullMessage represents a progress message of an image pull.
+type PullMessage struct {
+	// ID is the layer the message refers to, empty for the messages about the whole image.
+	ID       string `json:"id"`
+	Status   string `json:"status"`
+	Progress string `json:"progress"`
+	Error    string `json:"error"`
+}
+
+type onPullProgressFunc func(message PullMessage)
 
 // DockerClient is a wrapper around the Docker client
 // to handle the CLI features.
@@ -59,30 +80,70 @@ func (client *DockerClient) Close() error {
 	return client.docker.Close()
 }
 
-// PullCatImage pulls the specified version of the cat image
-func (client *DockerClient) PullCatImage(ctx context.Context, version string) error {
-	fullImageURL := fmt.Sprintf("cheshire-cat-ai:%s", version)
+// CatImageExists checks whether the specified cat image is already available locally
+func (client *DockerClient) CatImageExists(ctx context.Context, catImage string) (bool, error) {
+	_, _, err := client.docker.ImageInspectWithRaw(ctx, catImage)
+	if docker.IsErrNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
 
-	slog.Debug("Pulling image", slog.String("image", fullImageURL))
-	result, err := client.docker.ImagePull(ctx, fullImageURL, image.PullOptions{})
+// PullCatImage pulls the specified cat image (in the "image:version" format)
+//
+// The pull fails when the daemon reports an error in the progress messages,
+// e.g. an unknown tag or a network failure while downloading a layer.
+func (client *DockerClient) PullCatImage(ctx context.Context, catImage string) error {
+	slog.Debug("Pulling image", slog.String("image", catImage))
+	result, err := client.docker.ImagePull(ctx, catImage, image.PullOptions{})
 	if err != nil {
 		return err
 	}
 	defer result.Close()
 
-	if client.onPullProgress == nil {
-		io.Copy(io.Discard, result)
-	} else {
-		proxyBuffer := new(bytes.Buffer)
-		client.onPullProgress(proxyBuffer)
-		io.Copy(proxyBuffer, result)
+	decoder := json.NewDecoder(result)
+	for {
+		var message PullMessage
+		err = decoder.Decode(&message)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if message.Error != "" {
+			return ErrPullFailed(catImage, message.Error)
+		}
+		if client.onPullProgress != nil {
+			client.onPullProgress(message)
+		}
 	}
+}
 
-	return nil
+// CatContainerExists checks whether a container with the specified name exists,
+// and if so whether it is running.
+func (client *DockerClient) CatContainerExists(ctx context.Context, containerName string) (exists bool, running bool, err error) {
+	containerInfo, err := client.docker.ContainerInspect(ctx, containerName)
+	if docker.IsErrNotFound(err) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+
+	return true, containerInfo.State != nil && containerInfo.State.Running, nil
 }
 
 // https://docs.docker.com/engine/api/sdk/examples/
 
+// StartCatContainerConfig represents the parameters used to create and start a cat container.
+//
+// The folder paths must be absolute paths on the host machine.
 type StartCatContainerConfig struct {
 	CatImage              string
 	CatContainerName      string
@@ -92,25 +153,26 @@ type StartCatContainerConfig struct {
 	StaticFolderPath      string
 }
 
-// StartCatContainer starts the cheshire cat container with the specified config
+// StartCatContainer creates and starts the cheshire cat container with the specified config.
+//
+// It returns as soon as the container is started, use WaitCatReady to wait for the cat API.
 func (client *DockerClient) StartCatContainer(ctx context.Context, config StartCatContainerConfig) error {
 	dockerContainerConfig := &container.Config{
 		Tty:   false,
 		Image: config.CatImage,
-		Volumes: map[string]struct{}{
-			config.PluginFolderPath: {},
+		ExposedPorts: nat.PortSet{
+			catPort: {},
 		},
 	}
 
-	portBinding := nat.Port(fmt.Sprintf("%d/tcp", config.CatContainerBoundPort))
 	dockerHostConfig := &container.HostConfig{
 		Binds: []string{
-			fmt.Sprintf("%s:%s", config.PluginFolderPath, "/app/cat/plugins"),
-			fmt.Sprintf("%s:%s", config.DataFolderPath, "/app/cat/data"),
-			fmt.Sprintf("%s:%s", config.StaticFolderPath, "/app/cat/static"),
+			fmt.Sprintf("%s:%s", config.PluginFolderPath, CatPluginsPath),
+			fmt.Sprintf("%s:%s", config.DataFolderPath, CatDataPath),
+			fmt.Sprintf("%s:%s", config.StaticFolderPath, CatStaticPath),
 		},
-		PortBindings: map[nat.Port][]nat.PortBinding{
-			portBinding: {{HostPort: "80"}},
+		PortBindings: nat.PortMap{
+			catPort: {{HostPort: strconv.Itoa(config.CatContainerBoundPort)}},
 		},
 	}
 	result, err := client.docker.ContainerCreate(ctx, dockerContainerConfig, dockerHostConfig, nil, nil, config.CatContainerName)
@@ -118,21 +180,7 @@ func (client *DockerClient) StartCatContainer(ctx context.Context, config StartC
 		return err
 	}
 
-	err = client.docker.ContainerStart(ctx, result.ID, container.StartOptions{})
-	if err != nil {
-		return err
-	}
-
-	statusCh, errCh := client.docker.ContainerWait(ctx, result.ID, container.WaitConditionNotRunning)
-	select {
-	case err := <-errCh:
-		if err != nil {
-			return err
-		}
-	case <-statusCh:
-	}
-
-	return nil
+	return client.docker.ContainerStart(ctx, result.ID, container.StartOptions{})
 }
 
 // StopCatContainer stops the specified cat container
@@ -147,3 +195,23 @@ func (client *DockerClient) RemoveCatContainer(ctx context.Context, containerNam
 		RemoveVolumes: false,
 	})
 }
+
+// RestartCatContainer restarts the specified cat container
+func (client *DockerClient) RestartCatContainer(ctx context.Context, containerName string) error {
+	return client.docker.ContainerRestart(ctx, containerName, container.StopOptions{})
+}
+
+// StartStoppedCatContainer starts the specified cat container, which must already exist
+func (client *DockerClient) StartStoppedCatContainer(ctx context.Context, containerName string) error {
+	return client.docker.ContainerStart(ctx, containerName, container.StartOptions{})
+}
+
+// PauseCatContainer freezes the processes of the specified cat container
+func (client *DockerClient) PauseCatContainer(ctx context.Context, containerName string) error {
+	return client.docker.ContainerPause(ctx, containerName)
+}
+
+// UnpauseCatContainer resumes the processes of the specified paused cat container
+func (client *DockerClient) UnpauseCatContainer(ctx context.Context, containerName string) error {
+	return client.docker.ContainerUnpause(ctx, containerName)
+}
@@ -0,0 +1,90 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package docker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	docker "github.com/docker/docker/client"
+)
+
+func TestPullCatImage(t *testing.T) {
+	tests := []struct {
+		name         string
+		stream       string
+		wantMessages int
+		wantErr      string
+	}{
+		{
+			name: "pulled",
+			stream: `{"status": "Pulling from cheshire-cat-ai/core", "id": "1.7.1"}
+{"status": "Downloading", "progress": "[=>   ]", "id": "a1b2"}
+{"status": "Status: Downloaded newer image for ghcr.io/cheshire-cat-ai/core:1.7.1"}`,
+			wantMessages: 3,
+		},
+		{
+			name: "error in the stream",
+			stream: `{"status": "Pulling from cheshire-cat-ai/core", "id": "1.7.1"}
+{"errorDetail": {"message": "manifest unknown"}, "error": "manifest unknown"}
+{"status": "never read"}`,
+			wantMessages: 1,
+			wantErr:      "manifest unknown",
+		},
+		{
+			name:         "truncated stream",
+			stream:       `{"status": "Downloading", "id": "a1b2"`,
+			wantMessages: 0,
+			wantErr:      "unexpected EOF",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				if !strings.HasSuffix(request.URL.Path, "/images/create") {
+					http.NotFound(writer, request)
+					return
+				}
+				_, _ = writer.Write([]byte(test.stream))
+			}))
+			defer server.Close()
+
+			dockerClient, err := docker.NewClientWithOpts(docker.WithHost("tcp://"+server.Listener.Addr().String()), docker.WithVersion("1.45"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			messages := 0
+			client := &DockerClient{docker: dockerClient, onPullProgress: func(message PullMessage) {
+				messages++
+			}}
+			defer client.Close()
+
+			err = client.PullCatImage(context.Background(), "ghcr.io/cheshire-cat-ai/core:1.7.1")
+			if test.wantErr == "" && err != nil || test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)) {
+				t.Errorf("PullCatImage() error = %v, want %q", err, test.wantErr)
+			}
+			if messages != test.wantMessages {
+				t.Errorf("PullCatImage() reported %d messages, want %d", messages, test.wantMessages)
+			}
+		})
+	}
+}
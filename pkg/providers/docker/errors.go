@@ -0,0 +1,49 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package docker
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNilReadyURL = fmt.Errorf("no URL provided to check the cat readiness")
+)
+
+// ErrCatCrashed is returned when the cat container stops while booting.
+func ErrCatCrashed(containerName string, exitCode int, lastLogLines []string) error {
+	return fmt.Errorf(
+		"the cat container %q stopped during boot with exit code %d, last log lines:\n%s",
+		containerName, exitCode, strings.Join(lastLogLines, "\n"),
+	)
+}
+
+// ErrCatNotReady is returned when the cat API does not answer within the timeout.
+func ErrCatNotReady(containerName string, timeout time.Duration, lastErr error, lastLogLines []string) error {
+	return fmt.Errorf(
+		"the cat container %q did not become ready within %s (last error: %v), last log lines:\n%s",
+		containerName, timeout, lastErr, strings.Join(lastLogLines, "\n"),
+	)
+}
+
+// ErrPullFailed is returned when the daemon reports an error while pulling the cat image.
+func ErrPullFailed(catImage string, message string) error {
+	return fmt.Errorf("cannot pull the cat image %q: %s", catImage, message)
+}
@@ -0,0 +1,136 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package docker
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// StreamCatLogsConfig represents the parameters used to stream the cat container logs.
+type StreamCatLogsConfig struct {
+	// Follow keeps streaming the new log lines until the context is done.
+	Follow bool
+	// Since shows only the logs since a timestamp (RFC3339) or relative duration (e.g. 10m).
+	Since string
+	// Tail is the number of lines to show from the end of the logs ("all" or a number).
+	Tail string
+	// Timestamps prefixes each log line with its RFC3339Nano timestamp.
+	Timestamps bool
+}
+
+// StreamCatLogs writes the cat container logs to the specified writers,
+// demultiplexing the container stdout and stderr.
+func (client *DockerClient) StreamCatLogs(ctx context.Context, containerName string, config StreamCatLogsConfig, stdout io.Writer, stderr io.Writer) error {
+	tail := config.Tail
+	if tail == "" {
+		tail = "all"
+	}
+
+	logs, err := client.docker.ContainerLogs(ctx, containerName, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     config.Follow,
+		Since:      config.Since,
+		Tail:       tail,
+		Timestamps: config.Timestamps,
+	})
+	if err != nil {
+		return err
+	}
+	defer logs.Close()
+
+	_, err = stdcopy.StdCopy(stdout, stderr, logs)
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	return err
+}
+
+// CatLogRecord represents a parsed cat log line.
+type CatLogRecord struct {
+	Time    time.Time
+	Level   slog.Level
+	Source  string
+	Message string
+}
+
+var (
+	// catLogLineRegexp matches the cat logger lines, e.g.
+	// "[2024-06-10 10:00:00.123] INFO   cat.mad_hatter.mad_hatter..find_plugins::69 => message"
+	catLogLineRegexp = regexp.MustCompile(`^\[(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}(?:\.\d+)?)\]\s+([A-Z]+)\s+(\S+)?\s*(?:=>\s*)?(.*)$`)
+	// uvicornLogLineRegexp matches the web server lines, e.g. "INFO:     Started server process [1]"
+	uvicornLogLineRegexp = regexp.MustCompile(`^([A-Z]+):\s+(.*)$`)
+)
+
+// ParseCatLogLine parses a line of the cat logs.
+//
+// When the line is prefixed by the docker timestamp (see StreamCatLogsConfig.Timestamps) it is used as record time.
+// Lines not matching any known format are returned as info records with the whole line as message.
+func ParseCatLogLine(line string) CatLogRecord {
+	record := CatLogRecord{
+		Level:   slog.LevelInfo,
+		Message: line,
+	}
+
+	dockerTimestamp, rest, found := strings.Cut(line, " ")
+	if found {
+		timestamp, err := time.Parse(time.RFC3339Nano, dockerTimestamp)
+		if err == nil {
+			record.Time = timestamp
+			record.Message = rest
+			line = rest
+		}
+	}
+
+	if matches := catLogLineRegexp.FindStringSubmatch(line); matches != nil {
+		timestamp, err := time.ParseInLocation("2006-01-02 15:04:05.999", matches[1], time.Local)
+		if err == nil && record.Time.IsZero() {
+			record.Time = timestamp
+		}
+		record.Level = parseCatLogLevel(matches[2])
+		record.Source = matches[3]
+		record.Message = matches[4]
+	} else if matches := uvicornLogLineRegexp.FindStringSubmatch(line); matches != nil {
+		record.Level = parseCatLogLevel(matches[1])
+		record.Message = matches[2]
+	}
+
+	return record
+}
+
+func parseCatLogLevel(level string) slog.Level {
+	switch level {
+	case "TRACE", "DEBUG":
+		return slog.LevelDebug
+	case "WARN", "WARNING":
+		return slog.LevelWarn
+	case "ERROR", "CRITICAL":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
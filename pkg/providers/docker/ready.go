@@ -0,0 +1,152 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type httpClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// WaitCatReadyConfig represents the parameters used to wait for the cat to be ready.
+type WaitCatReadyConfig struct {
+	// CatContainerName is the name of the container to check.
+	CatContainerName string
+	// URL is the cat HTTP endpoint polled until it answers successfully.
+	URL string
+	// Timeout is the maximum time to wait for the cat to be ready.
+	Timeout time.Duration
+	// InitialBackoff is the delay before the first retry, doubled on each retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between retries.
+	MaxBackoff time.Duration
+	// LogLines is the number of container log lines included in the returned errors.
+	LogLines int
+	// HTTPClient is the client used to poll the URL (default is http.DefaultClient).
+	HTTPClient httpClient
+}
+
+// WaitCatReady waits until the cat container is running and its HTTP API answers.
+//
+// It returns an error including the last container log lines
+// if the container stops while booting or the timeout expires.
+func (client *DockerClient) WaitCatReady(ctx context.Context, config WaitCatReadyConfig) error {
+	if config.URL == "" {
+		return ErrNilReadyURL
+	}
+
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	backoff := config.InitialBackoff
+	if backoff <= 0 {
+		backoff = 500 * time.Millisecond
+	}
+	maxBackoff := config.MaxBackoff
+	if maxBackoff < backoff {
+		maxBackoff = backoff
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
+
+	var lastErr error
+	for {
+		containerInfo, err := client.docker.ContainerInspect(waitCtx, config.CatContainerName)
+		if err != nil && waitCtx.Err() == nil {
+			return err
+		}
+		if err == nil && containerInfo.State != nil && !containerInfo.State.Running && !containerInfo.State.Restarting {
+			return ErrCatCrashed(config.CatContainerName, containerInfo.State.ExitCode, client.lastLogLines(ctx, config))
+		}
+
+		if err == nil {
+			lastErr = pingCat(waitCtx, httpClient, config.URL)
+			if lastErr == nil {
+				return nil
+			}
+			slog.Debug("The cat is not ready yet", slog.String("error", lastErr.Error()), slog.Duration("retry_in", backoff))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-waitCtx.Done():
+			return ErrCatNotReady(config.CatContainerName, config.Timeout, lastErr, client.lastLogLines(ctx, config))
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// pingCat performs a GET request to the specified URL, expecting a successful status code.
+func pingCat(ctx context.Context, httpClient httpClient, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("request failed with status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// lastLogLines returns the last log lines of the cat container, ignoring errors
+// since they are used only to enrich other errors.
+func (client *DockerClient) lastLogLines(ctx context.Context, config WaitCatReadyConfig) []string {
+	logLines := config.LogLines
+	if logLines <= 0 {
+		logLines = 20
+	}
+
+	var output bytes.Buffer
+	err := client.StreamCatLogs(ctx, config.CatContainerName, StreamCatLogsConfig{
+		Tail: strconv.Itoa(logLines),
+	}, &output, &output)
+	if err != nil {
+		return []string{fmt.Sprintf("<unable to read container logs: %v>", err)}
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(&output)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	return lines
+}
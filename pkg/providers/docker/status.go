@@ -0,0 +1,116 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package docker
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	docker "github.com/docker/docker/client"
+)
+
+// CatContainerStatus represents the state of a cat container.
+type CatContainerStatus struct {
+	ContainerName string        `json:"container_name"`
+	ContainerID   string        `json:"container_id,omitempty"`
+	Exists        bool          `json:"exists"`
+	State         string        `json:"state"`
+	Running       bool          `json:"running"`
+	StartedAt     *time.Time    `json:"started_at,omitempty"`
+	Uptime        time.Duration `json:"-"`
+	Image         string        `json:"image,omitempty"`
+	ImageDigest   string        `json:"image_digest,omitempty"`
+	HostPort      int           `json:"host_port,omitempty"`
+	PluginsFolder string        `json:"plugins_folder,omitempty"`
+	DataFolder    string        `json:"data_folder,omitempty"`
+	StaticFolder  string        `json:"static_folder,omitempty"`
+}
+
+// InspectCatContainer returns the status of the specified cat container.
+//
+// A missing container is not an error, the returned status has Exists set to false.
+func (client *DockerClient) InspectCatContainer(ctx context.Context, containerName string) (*CatContainerStatus, error) {
+	status := &CatContainerStatus{
+		ContainerName: containerName,
+		State:         "missing",
+	}
+
+	containerInfo, err := client.docker.ContainerInspect(ctx, containerName)
+	if docker.IsErrNotFound(err) {
+		return status, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	status.Exists = true
+	status.ContainerID = containerInfo.ID
+	if containerInfo.State != nil {
+		status.State = containerInfo.State.Status
+		status.Running = containerInfo.State.Running
+
+		startedAt, err := time.Parse(time.RFC3339Nano, containerInfo.State.StartedAt)
+		if err == nil && status.Running {
+			status.StartedAt = &startedAt
+			status.Uptime = time.Since(startedAt).Truncate(time.Second)
+		}
+	}
+
+	if containerInfo.Config != nil {
+		status.Image = containerInfo.Config.Image
+	}
+	status.ImageDigest = containerInfo.Image
+
+	imageInfo, _, err := client.docker.ImageInspectWithRaw(ctx, containerInfo.Image)
+	if err == nil && len(imageInfo.RepoDigests) > 0 {
+		status.ImageDigest = imageInfo.RepoDigests[0]
+	}
+
+	if containerInfo.NetworkSettings != nil {
+		for _, binding := range containerInfo.NetworkSettings.Ports[catPort] {
+			hostPort, err := strconv.Atoi(binding.HostPort)
+			if err == nil {
+				status.HostPort = hostPort
+				break
+			}
+		}
+	}
+	if status.HostPort == 0 && containerInfo.HostConfig != nil {
+		for _, binding := range containerInfo.HostConfig.PortBindings[catPort] {
+			hostPort, err := strconv.Atoi(binding.HostPort)
+			if err == nil {
+				status.HostPort = hostPort
+				break
+			}
+		}
+	}
+
+	for _, mount := range containerInfo.Mounts {
+		switch mount.Destination {
+		case CatPluginsPath:
+			status.PluginsFolder = mount.Source
+		case CatDataPath:
+			status.DataFolder = mount.Source
+		case CatStaticPath:
+			status.StaticFolder = mount.Source
+		}
+	}
+
+	return status, nil
+}
@@ -0,0 +1,448 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package schema validates the cat settings against their JSON schema.
+//
+// Only the subset of JSON schema generated by pydantic for the cat settings models is supported.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Schema represents a JSON schema, as generated by pydantic.
+type Schema struct {
+	Type             any                `json:"type,omitempty"`
+	Title            string             `json:"title,omitempty"`
+	Description      string             `json:"description,omitempty"`
+	Properties       map[string]*Schema `json:"properties,omitempty"`
+	Required         []string           `json:"required,omitempty"`
+	Enum             []any              `json:"enum,omitempty"`
+	Const            any                `json:"const,omitempty"`
+	Default          any                `json:"default,omitempty"`
+	Minimum          *float64           `json:"minimum,omitempty"`
+	Maximum          *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum *float64           `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum *float64           `json:"exclusiveMaximum,omitempty"`
+	MinLength        *int               `json:"minLength,omitempty"`
+	MaxLength        *int               `json:"maxLength,omitempty"`
+	Pattern          string             `json:"pattern,omitempty"`
+	Format           string             `json:"format,omitempty"`
+	Items            *Schema            `json:"items,omitempty"`
+	AnyOf            []*Schema          `json:"anyOf,omitempty"`
+	AllOf            []*Schema          `json:"allOf,omitempty"`
+	OneOf            []*Schema          `json:"oneOf,omitempty"`
+	Ref              string             `json:"$ref,omitempty"`
+	Defs             map[string]*Schema `json:"$defs,omitempty"`
+	Definitions      map[string]*Schema `json:"definitions,omitempty"`
+}
+
+// Parse converts the raw JSON schema, as returned by the cat API.
+func Parse(raw map[string]any) (*Schema, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := new(Schema)
+	err = json.Unmarshal(data, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+// ValidationError represents a value not matching the schema.
+type ValidationError struct {
+	// Path is the dot separated path of the invalid value.
+	Path    string
+	Message string
+}
+
+func (err *ValidationError) Error() string {
+	if err.Path == "" {
+		return err.Message
+	}
+
+	return fmt.Sprintf("%s: %s", err.Path, err.Message)
+}
+
+// Validate checks the object against the schema, returning all the problems found.
+//
+// Properties not defined in the schema are reported as errors as well,
+// to catch typos in the keys.
+func (schema *Schema) Validate(value map[string]any) []error {
+	var problems []error
+	schema.validateObject(schema, "", value, &problems, true)
+	return problems
+}
+
+// Types returns the JSON types allowed by the schema, resolving references and unions.
+func (schema *Schema) Types(root *Schema) []string {
+	resolved := root.resolve(schema)
+
+	var types []string
+	switch typeValue := resolved.Type.(type) {
+	case string:
+		types = append(types, typeValue)
+	case []any:
+		for _, item := range typeValue {
+			if typeName, ok := item.(string); ok {
+				types = append(types, typeName)
+			}
+		}
+	}
+
+	for _, options := range [][]*Schema{resolved.AnyOf, resolved.OneOf, resolved.AllOf} {
+		for _, option := range options {
+			types = append(types, option.Types(root)...)
+		}
+	}
+	if len(types) == 0 && len(resolved.Enum) > 0 {
+		for _, item := range resolved.Enum {
+			types = append(types, jsonType(item))
+		}
+	}
+
+	return types
+}
+
+// Property returns the schema of the top level property, resolving references.
+func (schema *Schema) Property(name string) (*Schema, bool) {
+	property, exists := schema.Properties[name]
+	if !exists {
+		return nil, false
+	}
+
+	return schema.resolve(property), true
+}
+
+// ParseValue converts the raw command line value of the top level property to the type required by the schema.
+func (schema *Schema) ParseValue(name string, raw string) (any, error) {
+	property, exists := schema.Property(name)
+	if !exists {
+		return nil, &ValidationError{Path: name, Message: "unknown setting"}
+	}
+
+	types := property.Types(schema)
+	if len(types) == 0 {
+		types = []string{"string"}
+	}
+
+	var lastErr error
+	for _, typeName := range types {
+		value, err := parseTyped(typeName, raw)
+		if err == nil {
+			return value, nil
+		}
+		lastErr = err
+	}
+
+	return nil, &ValidationError{Path: name, Message: lastErr.Error()}
+}
+
+// Defaults returns the default values of the top level properties.
+func (schema *Schema) Defaults() map[string]any {
+	defaults := make(map[string]any)
+	for name, property := range schema.Properties {
+		resolved := schema.resolve(property)
+		if property.Default != nil {
+			defaults[name] = property.Default
+		} else if resolved.Default != nil {
+			defaults[name] = resolved.Default
+		}
+	}
+
+	return defaults
+}
+
+// PropertyNames returns the sorted names of the top level properties.
+func (schema *Schema) PropertyNames() []string {
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func parseTyped(typeName string, raw string) (any, error) {
+	switch typeName {
+	case "string":
+		return raw, nil
+	case "integer":
+		value, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", raw)
+		}
+		return value, nil
+	case "number":
+		value, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", raw)
+		}
+		return value, nil
+	case "boolean":
+		value, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", raw)
+		}
+		return value, nil
+	case "null":
+		if raw != "null" && raw != "" {
+			return nil, fmt.Errorf("%q is not null", raw)
+		}
+		return nil, nil
+	default:
+		var value any
+		err := json.Unmarshal([]byte(raw), &value)
+		if err != nil || jsonType(value) != typeName {
+			return nil, fmt.Errorf("%q is not a valid JSON %s", raw, typeName)
+		}
+		return value, nil
+	}
+}
+
+// resolve follows the $ref of the schema, looking up the definitions of the root schema.
+func (root *Schema) resolve(schema *Schema) *Schema {
+	for depth := 0; schema.Ref != "" && depth < 32; depth++ {
+		name := schema.Ref[strings.LastIndex(schema.Ref, "/")+1:]
+
+		definition, exists := root.Defs[name]
+		if !exists {
+			definition, exists = root.Definitions[name]
+		}
+		if !exists {
+			return schema
+		}
+
+		schema = definition
+	}
+
+	return schema
+}
+
+func (root *Schema) validateObject(schema *Schema, path string, value map[string]any, problems *[]error, rejectUnknown bool) {
+	for _, name := range schema.Required {
+		if _, exists := value[name]; !exists {
+			*problems = append(*problems, &ValidationError{Path: joinPath(path, name), Message: "required value is missing"})
+		}
+	}
+
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property, exists := schema.Properties[name]
+		if !exists {
+			if rejectUnknown && len(schema.Properties) > 0 {
+				*problems = append(*problems, &ValidationError{Path: joinPath(path, name), Message: "unknown setting"})
+			}
+			continue
+		}
+
+		root.validateValue(property, joinPath(path, name), value[name], problems)
+	}
+}
+
+func (root *Schema) validateValue(schema *Schema, path string, value any, problems *[]error) {
+	schema = root.resolve(schema)
+
+	if len(schema.AnyOf) > 0 || len(schema.OneOf) > 0 {
+		options := append(append([]*Schema{}, schema.AnyOf...), schema.OneOf...)
+		for _, option := range options {
+			var optionProblems []error
+			root.validateValue(option, path, value, &optionProblems)
+			if len(optionProblems) == 0 {
+				return
+			}
+		}
+
+		*problems = append(*problems, &ValidationError{Path: path, Message: fmt.Sprintf("%v does not match any of the allowed types %v", value, schema.Types(root))})
+		return
+	}
+
+	for _, option := range schema.AllOf {
+		root.validateValue(option, path, value, problems)
+	}
+
+	if types := schema.directTypes(); len(types) > 0 && !matchesAnyType(value, types) {
+		*problems = append(*problems, &ValidationError{Path: path, Message: fmt.Sprintf("expected %s, got %s", strings.Join(types, " or "), jsonType(value))})
+		return
+	}
+
+	if len(schema.Enum) > 0 && !containsValue(schema.Enum, value) {
+		*problems = append(*problems, &ValidationError{Path: path, Message: fmt.Sprintf("%v is not one of %v", value, schema.Enum)})
+	}
+	if schema.Const != nil && !equalValues(schema.Const, value) {
+		*problems = append(*problems, &ValidationError{Path: path, Message: fmt.Sprintf("expected %v", schema.Const)})
+	}
+
+	switch typedValue := value.(type) {
+	case string:
+		length := len([]rune(typedValue))
+		if schema.MinLength != nil && length < *schema.MinLength {
+			*problems = append(*problems, &ValidationError{Path: path, Message: fmt.Sprintf("must be at least %d characters long", *schema.MinLength)})
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			*problems = append(*problems, &ValidationError{Path: path, Message: fmt.Sprintf("must be at most %d characters long", *schema.MaxLength)})
+		}
+		if schema.Pattern != "" {
+			pattern, err := regexp.Compile(schema.Pattern)
+			if err == nil && !pattern.MatchString(typedValue) {
+				*problems = append(*problems, &ValidationError{Path: path, Message: fmt.Sprintf("does not match the pattern %s", schema.Pattern)})
+			}
+		}
+	case map[string]any:
+		root.validateObject(schema, path, typedValue, problems, false)
+	case []any:
+		if schema.Items != nil {
+			for index, item := range typedValue {
+				root.validateValue(schema.Items, fmt.Sprintf("%s[%d]", path, index), item, problems)
+			}
+		}
+	}
+
+	if number, ok := toFloat(value); ok {
+		if schema.Minimum != nil && number < *schema.Minimum {
+			*problems = append(*problems, &ValidationError{Path: path, Message: fmt.Sprintf("must be >= %v", *schema.Minimum)})
+		}
+		if schema.Maximum != nil && number > *schema.Maximum {
+			*problems = append(*problems, &ValidationError{Path: path, Message: fmt.Sprintf("must be <= %v", *schema.Maximum)})
+		}
+		if schema.ExclusiveMinimum != nil && number <= *schema.ExclusiveMinimum {
+			*problems = append(*problems, &ValidationError{Path: path, Message: fmt.Sprintf("must be > %v", *schema.ExclusiveMinimum)})
+		}
+		if schema.ExclusiveMaximum != nil && number >= *schema.ExclusiveMaximum {
+			*problems = append(*problems, &ValidationError{Path: path, Message: fmt.Sprintf("must be < %v", *schema.ExclusiveMaximum)})
+		}
+	}
+}
+
+// directTypes returns the types declared by the schema itself, without unions.
+func (schema *Schema) directTypes() []string {
+	switch typeValue := schema.Type.(type) {
+	case string:
+		return []string{typeValue}
+	case []any:
+		var types []string
+		for _, item := range typeValue {
+			if typeName, ok := item.(string); ok {
+				types = append(types, typeName)
+			}
+		}
+		return types
+	default:
+		return nil
+	}
+}
+
+func matchesAnyType(value any, types []string) bool {
+	valueType := jsonType(value)
+	for _, typeName := range types {
+		if typeName == valueType || (typeName == "number" && valueType == "integer") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// jsonType returns the JSON type name of the decoded value.
+func jsonType(value any) string {
+	switch typedValue := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	default:
+		number, ok := toFloat(typedValue)
+		if !ok {
+			return fmt.Sprintf("%T", value)
+		}
+		if number == math.Trunc(number) {
+			return "integer"
+		}
+		return "number"
+	}
+}
+
+func toFloat(value any) (float64, bool) {
+	switch number := value.(type) {
+	case float64:
+		return number, true
+	case float32:
+		return float64(number), true
+	case int:
+		return float64(number), true
+	case int64:
+		return float64(number), true
+	case int32:
+		return float64(number), true
+	case uint64:
+		return float64(number), true
+	case json.Number:
+		parsed, err := number.Float64()
+		return parsed, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func containsValue(values []any, value any) bool {
+	for _, item := range values {
+		if equalValues(item, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func equalValues(a any, b any) bool {
+	if numberA, ok := toFloat(a); ok {
+		numberB, ok := toFloat(b)
+		return ok && numberA == numberB
+	}
+
+	return fmt.Sprint(a) == fmt.Sprint(b) && jsonType(a) == jsonType(b)
+}
+
+func joinPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
@@ -0,0 +1,197 @@
+/*
+Copyright © 2024 Alessandro Sanino <alessandro@sanino.dev>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testSchema is a settings schema as generated by pydantic for a plugin.
+const testSchema = `{
+	"title": "PluginSettings",
+	"type": "object",
+	"properties": {
+		"name": {"title": "Name", "type": "string", "minLength": 2, "maxLength": 8, "pattern": "^[a-z]+$"},
+		"temperature": {"title": "Temperature", "type": "number", "default": 0.7, "minimum": 0, "maximum": 1},
+		"retries": {"title": "Retries", "type": "integer", "default": 3, "exclusiveMinimum": 0},
+		"enabled": {"title": "Enabled", "type": "boolean", "default": true},
+		"mode": {"$ref": "#/$defs/Mode", "default": "fast"},
+		"tags": {"title": "Tags", "type": "array", "items": {"type": "string"}},
+		"limit": {"title": "Limit", "anyOf": [{"type": "integer"}, {"type": "null"}]},
+		"nested": {"title": "Nested", "type": "object", "properties": {"depth": {"type": "integer"}}, "required": ["depth"]},
+		"legacy": {"$ref": "#/definitions/Legacy"}
+	},
+	"required": ["name"],
+	"$defs": {
+		"Mode": {"title": "Mode", "enum": ["fast", "accurate"], "type": "string"}
+	},
+	"definitions": {
+		"Legacy": {"title": "Legacy", "type": "string", "default": "on"}
+	}
+}`
+
+// parseTestSchema parses the raw JSON schema.
+func parseTestSchema(t *testing.T, raw string) *Schema {
+	t.Helper()
+
+	var decoded map[string]any
+	err := json.Unmarshal([]byte(raw), &decoded)
+	if err != nil {
+		t.Fatalf("invalid test schema: %v", err)
+	}
+
+	schema, err := Parse(decoded)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	return schema
+}
+
+func TestSchemaValidate(t *testing.T) {
+	schema := parseTestSchema(t, testSchema)
+
+	tests := []struct {
+		name  string
+		value map[string]any
+		// want are the substrings of the expected problems, in order.
+		want []string
+	}{
+		{name: "valid", value: map[string]any{"name": "abc", "temperature": 0.5, "retries": float64(2), "enabled": false}},
+		{name: "valid reference and union", value: map[string]any{"name": "abc", "mode": "accurate", "limit": nil, "legacy": "off"}},
+		{name: "valid nested object and array", value: map[string]any{"name": "abc", "nested": map[string]any{"depth": float64(1)}, "tags": []any{"a", "b"}}},
+		{name: "integer is a number", value: map[string]any{"name": "abc", "temperature": float64(1)}},
+		{name: "missing required", value: map[string]any{}, want: []string{"name: required value is missing"}},
+		{name: "unknown top level setting", value: map[string]any{"name": "abc", "temprature": 0.5}, want: []string{"temprature: unknown setting"}},
+		{name: "wrong type", value: map[string]any{"name": "abc", "enabled": "yes"}, want: []string{"enabled: expected boolean, got string"}},
+		{name: "number is not an integer", value: map[string]any{"name": "abc", "retries": 1.5}, want: []string{"retries: expected integer, got number"}},
+		{name: "minimum", value: map[string]any{"name": "abc", "temperature": -0.1}, want: []string{"temperature: must be >= 0"}},
+		{name: "maximum", value: map[string]any{"name": "abc", "temperature": 1.5}, want: []string{"temperature: must be <= 1"}},
+		{name: "exclusive minimum", value: map[string]any{"name": "abc", "retries": float64(0)}, want: []string{"retries: must be > 0"}},
+		{name: "min length", value: map[string]any{"name": "a"}, want: []string{"name: must be at least 2 characters long"}},
+		{name: "max length", value: map[string]any{"name": "abcdefghi"}, want: []string{"name: must be at most 8 characters long"}},
+		{name: "pattern", value: map[string]any{"name": "ab1"}, want: []string{"name: does not match the pattern"}},
+		{name: "enum through reference", value: map[string]any{"name": "abc", "mode": "slow"}, want: []string{"mode: slow is not one of [fast accurate]"}},
+		{name: "union", value: map[string]any{"name": "abc", "limit": "ten"}, want: []string{"limit: ten does not match any of the allowed types [integer null]"}},
+		{name: "array items", value: map[string]any{"name": "abc", "tags": []any{"a", float64(2)}}, want: []string{"tags[1]: expected string, got integer"}},
+		{name: "nested required", value: map[string]any{"name": "abc", "nested": map[string]any{}}, want: []string{"nested.depth: required value is missing"}},
+		{
+			name:  "all the problems",
+			value: map[string]any{"enabled": "no", "temperature": float64(2)},
+			want:  []string{"name: required value is missing", "enabled: expected boolean", "temperature: must be <= 1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			problems := schema.Validate(test.value)
+			if len(problems) != len(test.want) {
+				t.Fatalf("Validate() = %v, want %d problems", problems, len(test.want))
+			}
+			for index, problem := range problems {
+				if !strings.Contains(problem.Error(), test.want[index]) {
+					t.Errorf("Validate()[%d] = %q, want it to contain %q", index, problem, test.want[index])
+				}
+			}
+		})
+	}
+}
+
+func TestSchemaParseValue(t *testing.T) {
+	schema := parseTestSchema(t, testSchema)
+
+	tests := []struct {
+		name     string
+		property string
+		raw      string
+		want     any
+		wantErr  bool
+	}{
+		{name: "string", property: "name", raw: "abc", want: "abc"},
+		{name: "number", property: "temperature", raw: "0.5", want: 0.5},
+		{name: "invalid number", property: "temperature", raw: "warm", wantErr: true},
+		{name: "integer", property: "retries", raw: " 5 ", want: int64(5)},
+		{name: "invalid integer", property: "retries", raw: "1.5", wantErr: true},
+		{name: "boolean", property: "enabled", raw: "false", want: false},
+		{name: "invalid boolean", property: "enabled", raw: "maybe", wantErr: true},
+		{name: "enum through reference", property: "mode", raw: "accurate", want: "accurate"},
+		{name: "array as JSON", property: "tags", raw: `["a","b"]`, want: []any{"a", "b"}},
+		{name: "invalid array", property: "tags", raw: "a,b", wantErr: true},
+		{name: "object as JSON", property: "nested", raw: `{"depth":1}`, want: map[string]any{"depth": float64(1)}},
+		{name: "union first type", property: "limit", raw: "10", want: int64(10)},
+		{name: "union null", property: "limit", raw: "null", want: nil},
+		{name: "union mismatch", property: "limit", raw: "ten", wantErr: true},
+		{name: "unknown setting", property: "missing", raw: "1", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := schema.ParseValue(test.property, test.raw)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ParseValue() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if err != nil {
+				var validationErr *ValidationError
+				if !errors.As(err, &validationErr) || validationErr.Path != test.property {
+					t.Errorf("ParseValue() error = %v, want a ValidationError for %q", err, test.property)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("ParseValue() = %#v, want %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSchemaDefaults(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   map[string]any
+	}{
+		{
+			name:   "property and reference defaults",
+			schema: testSchema,
+			want:   map[string]any{"temperature": 0.7, "retries": float64(3), "enabled": true, "mode": "fast", "legacy": "on"},
+		},
+		{
+			name:   "no defaults",
+			schema: `{"type": "object", "properties": {"name": {"type": "string"}}}`,
+			want:   map[string]any{},
+		},
+		{
+			name:   "no properties",
+			schema: `{"type": "object"}`,
+			want:   map[string]any{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := parseTestSchema(t, test.schema).Defaults()
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Defaults() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}